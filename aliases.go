@@ -0,0 +1,167 @@
+package valdn
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type alias struct {
+	rules   []string
+	nParams int
+	errMsg  string
+}
+
+var registeredAliases = make(map[string]*alias)
+
+// aliasParam matches the parameters of alias rules, $1 is the first parameter.
+var aliasParam = regexp.MustCompile(`\$[1-9]`)
+
+// AddAlias registers alias name of rules, so name can be used in tags and Rules instead of rules,
+// e.g. AddAlias("username", "required|minLen:3|maxLen:30") then `valdn:"username"`.
+// rules are separated by TagSeparator, and may have parameters $1 to $9 that are replaced by the comma separated
+// values the alias is used with, e.g. AddAlias("code", "kind:string|len:$1") then code:3.
+// Aliases may use other aliases, including aliases that are registered later.
+// Errors are the errors of the rules of the alias, use SetErrMsg to set an error message to the alias instead.
+// It panics if name is already registered as a rule or an alias, or if the alias makes a cycle of aliases.
+func AddAlias(name string, rules ...string) {
+	if _, ok := registeredRules[name]; ok {
+		panic("alias is already registered as a rule: " + name)
+	}
+	if _, ok := registeredAliases[name]; ok {
+		panic("alias is already registered")
+	}
+	a := &alias{}
+	for _, r := range rules {
+		a.rules = append(a.rules, strings.Split(r, TagSeparator)...)
+	}
+	for _, r := range a.rules {
+		for _, p := range aliasParam.FindAllString(r, -1) {
+			if n, _ := strconv.Atoi(p[1:]); n > a.nParams {
+				a.nParams = n
+			}
+		}
+	}
+	registeredAliases[name] = a
+	if cycle := aliasCycle(name, []string{name}); cycle != nil {
+		delete(registeredAliases, name)
+		panic(fmt.Errorf("alias cycle: %v", strings.Join(cycle, " -> ")))
+	}
+}
+
+// aliasCycle returns the aliases of the cycle that starts from alias name if it has one, path is the aliases from the
+// start of the cycle to name.
+func aliasCycle(name string, path []string) []string {
+	for _, ref := range aliasRefs(registeredAliases[name].rules) {
+		if ref == path[0] {
+			return append(path, ref)
+		}
+		if _, ok := registeredAliases[ref]; ok && !isIn(ref, path) {
+			if cycle := aliasCycle(ref, append(path, ref)); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// aliasRefs returns the names of rules, including the names of the rules of their groups.
+func aliasRefs(rules []string) []string {
+	var refs []string
+	for _, r := range rules {
+		rName, rVal := splitRuleNameAndRuleValue(r)
+		if _, _, ok := splitGroupRule(r); ok {
+			refs = append(refs, aliasRefs(splitGroup(rVal))...)
+			continue
+		}
+		refs = append(refs, rName)
+	}
+	return refs
+}
+
+// getAlias returns alias of rule r, its name and its value, and reports weather r is an alias.
+func getAlias(r string) (*alias, string, string, bool) {
+	aName, aVal := splitRuleNameAndRuleValue(r)
+	a, ok := registeredAliases[aName]
+	return a, aName, aVal, ok
+}
+
+// expand returns the rules of alias a named name, its parameters are replaced by the values of params separated by
+// commas.
+// It panics if params doesn't have the parameters of a.
+func (a *alias) expand(name string, params string) []string {
+	var values []string
+	if params != "" {
+		values = strings.Split(params, ",")
+	}
+	if len(values) != a.nParams {
+		panic(fmt.Errorf("alias %v expects %v parameters got %v", name, a.nParams, len(values)))
+	}
+	if a.nParams == 0 {
+		return a.rules
+	}
+	rules := make([]string, len(a.rules))
+	for i, r := range a.rules {
+		rules[i] = aliasParam.ReplaceAllStringFunc(r, func(p string) string {
+			n, _ := strconv.Atoi(p[1:])
+			return values[n-1]
+		})
+	}
+	return rules
+}
+
+// expandAliases returns rules with aliases replaced by their rules, aliases in groups are not replaced.
+// It panics if an alias is used with wrong number of parameters.
+func expandAliases(rules []string) []string {
+	if len(registeredAliases) == 0 {
+		return rules
+	}
+	expanded := make([]string, 0, len(rules))
+	for _, r := range rules {
+		if a, aName, aVal, ok := getAlias(r); ok {
+			expanded = append(expanded, expandAliases(a.expand(aName, aVal))...)
+			continue
+		}
+		expanded = append(expanded, r)
+	}
+	return expanded
+}
+
+// validateAlias validates val named name by the rules of alias a named aName of parameters aVal.
+// It returns the error message of the alias if it has one, or the error of its rule otherwise.
+func validateAlias(name string, val interface{}, a *alias, aName string, aVal string) error {
+	err := Validate(name, val, a.expand(aName, aVal))
+	if err != nil && a.errMsg != "" {
+		return errors.New(GetErrMsg(aName, aVal, name, val))
+	}
+	return err
+}
+
+// findRequired returns the name and the value of required rule of rules, or of the alias it's in if the alias has an
+// error message, and reports weather rules have required rule.
+func findRequired(rules []string) (string, string, bool) {
+	for _, r := range rules {
+		if a, aName, aVal, ok := getAlias(r); ok {
+			if rName, rVal, ok := findRequired(a.expand(aName, aVal)); ok {
+				if a.errMsg != "" {
+					return aName, aVal, true
+				}
+				return rName, rVal, true
+			}
+			continue
+		}
+		if rName, rVal := splitRuleNameAndRuleValue(r); rName == "required" {
+			return rName, rVal, true
+		}
+	}
+	return "", "", false
+}
+
+// isRegistered reports weather rule or alias name is registered.
+func isRegistered(name string) bool {
+	_, rule := registeredRules[name]
+	_, alias := registeredAliases[name]
+	return rule || alias
+}
@@ -0,0 +1,277 @@
+package valdn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func init() {
+	AddAlias("test_username", "required|minLen:3|maxLen:30|regex:^[a-z0-9_]+$")
+	AddAlias("test_code", "kind:string|len:$1")
+	AddAlias("test_between", "int", "between:$1,$2")
+	AddAlias("test_handle", "test_username|test_code:5")
+	AddAlias("test_attributed", "test_username")
+	SetErrMsg("test_attributed", "[name] must be a valid username")
+}
+
+func Test_AddAlias(t *testing.T) {
+	tests := []struct {
+		name       string
+		alias      string
+		rules      []string
+		wantParams int
+		wantPanic  bool
+	}{
+		{
+			name:  "test add alias",
+			alias: "test_add_alias",
+			rules: []string{"required|int", "min:1"},
+		},
+		{
+			name:       "test add alias with parameters",
+			alias:      "test_add_alias_params",
+			rules:      []string{"int|between:$1,$2|notIn:$1"},
+			wantParams: 2,
+		},
+		{
+			name:      "test add alias already exist",
+			alias:     "test_add_alias",
+			rules:     []string{"int"},
+			wantPanic: true,
+		},
+		{
+			name:      "test add alias of rule name",
+			alias:     "required",
+			rules:     []string{"int"},
+			wantPanic: true,
+		},
+		{
+			name:      "test add alias of itself",
+			alias:     "test_add_alias_self",
+			rules:     []string{"required|test_add_alias_self"},
+			wantPanic: true,
+		},
+		{
+			name:  "test add alias of alias that is not registered yet",
+			alias: "test_add_alias_forward",
+			rules: []string{"test_add_alias_cycle"},
+		},
+		{
+			name:      "test add alias of cycle",
+			alias:     "test_add_alias_cycle",
+			rules:     []string{"int|test_add_alias_forward"},
+			wantPanic: true,
+		},
+		{
+			name:      "test add alias of cycle in group",
+			alias:     "test_add_alias_cycle",
+			rules:     []string{"anyOf(email; all(int; test_add_alias_forward))"},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("AddAlias() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			AddAlias(tt.alias, tt.rules...)
+			if got := registeredAliases[tt.alias].nParams; got != tt.wantParams {
+				t.Errorf("AddAlias() parameters = %v, want %v", got, tt.wantParams)
+			}
+		})
+	}
+	if _, ok := registeredAliases["test_add_alias_cycle"]; ok {
+		t.Errorf("AddAlias() registered alias of cycle")
+	}
+}
+
+func Test_expandAliases(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []string
+		want      []string
+		wantPanic bool
+	}{
+		{
+			name:  "test expand aliases",
+			rules: []string{"test_username", "email"},
+			want:  []string{"required", "minLen:3", "maxLen:30", "regex:^[a-z0-9_]+$", "email"},
+		},
+		{
+			name:  "test expand aliases with parameters",
+			rules: []string{"test_code:4", "test_between:1,9"},
+			want:  []string{"kind:string", "len:4", "int", "between:1,9"},
+		},
+		{
+			name:  "test expand aliases of aliases",
+			rules: []string{"test_handle"},
+			want:  []string{"required", "minLen:3", "maxLen:30", "regex:^[a-z0-9_]+$", "kind:string", "len:5"},
+		},
+		{
+			name:  "test expand aliases in groups",
+			rules: []string{"anyOf(test_username; email)"},
+			want:  []string{"anyOf(test_username; email)"},
+		},
+		{
+			name:      "test expand aliases with missing parameters",
+			rules:     []string{"test_between:1"},
+			wantPanic: true,
+		},
+		{
+			name:      "test expand aliases with extra parameters",
+			rules:     []string{"test_username:1"},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("expandAliases() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := expandAliases(tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandAliases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Validate_aliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     interface{}
+		rules   []string
+		wantErr string
+	}{
+		{
+			name:    "test validate alias",
+			val:     "kyriakos_1",
+			rules:   []string{"test_username"},
+			wantErr: "",
+		},
+		{
+			name:    "test validate alias with error",
+			val:     "ky",
+			rules:   []string{"test_username"},
+			wantErr: GetErrMsg("minLen", "3", "user", "ky"),
+		},
+		{
+			name:    "test validate alias with parameters",
+			val:     12,
+			rules:   []string{"test_between:1,9"},
+			wantErr: GetErrMsg("between", "1,9", "user", 12),
+		},
+		{
+			name:    "test validate alias of error message",
+			val:     "Kyriakos",
+			rules:   []string{"test_attributed"},
+			wantErr: "user must be a valid username",
+		},
+		{
+			name:    "test validate alias in group",
+			val:     "test@test.test",
+			rules:   []string{"anyOf(test_username; email)"},
+			wantErr: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errString(Validate("user", tt.val, tt.rules)); got != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_findRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []string
+		wantName string
+		wantVal  string
+		wantOk   bool
+	}{
+		{
+			name:     "test find required",
+			rules:    []string{"int", "required"},
+			wantName: "required",
+			wantOk:   true,
+		},
+		{
+			name:     "test find required of alias",
+			rules:    []string{"test_handle"},
+			wantName: "required",
+			wantOk:   true,
+		},
+		{
+			name:     "test find required of alias of error message",
+			rules:    []string{"test_attributed"},
+			wantName: "test_attributed",
+			wantOk:   true,
+		},
+		{
+			name:   "test find required of rules that don't have it",
+			rules:  []string{"int", "test_code:2"},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotVal, gotOk := findRequired(tt.rules)
+			if gotName != tt.wantName || gotVal != tt.wantVal || gotOk != tt.wantOk {
+				t.Errorf("findRequired() = %v, %v, %v, want %v, %v, %v", gotName, gotVal, gotOk, tt.wantName,
+					tt.wantVal, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_ValidateCollection_aliases(t *testing.T) {
+	type user struct {
+		Username string `valdn:"test_username"`
+		Nickname string `valdn:"test_attributed"`
+		Code     string `valdn:"test_code:2"`
+	}
+	tests := []struct {
+		name  string
+		val   interface{}
+		rules Rules
+		want  Errors
+	}{
+		{
+			name: "test validate collection with aliases of tags",
+			val:  user{Username: "kyriakos", Nickname: "Kyriakos", Code: "EGY"},
+			want: Errors{
+				"Nickname": "Nickname must be a valid username",
+				"Code":     GetErrMsg("len", "2", "Code", "EGY"),
+			},
+		},
+		{
+			name:  "test validate collection with aliases of rules",
+			val:   map[string]interface{}{"age": 12},
+			rules: Rules{"age": {"test_between:1,9"}, "name": {"test_username"}, "nick": {"test_attributed"}},
+			want: Errors{
+				"age":  GetErrMsg("between", "1,9", "age", 12),
+				"name": GetErrMsg("required", "", "name", ""),
+				"nick": "nick must be a valid username",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateCollection(tt.val, tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateCollection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_convertReqVal_aliases(t *testing.T) {
+	got, err := convertReqVal("age", "5", []string{"test_between:1,9"})
+	if err != nil || got != int64(5) {
+		t.Errorf("convertReqVal() = %v, %v, want 5", got, err)
+	}
+}
@@ -0,0 +1,533 @@
+package valdn
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldRules builds the rules of a field by methods of typed parameters, so mistakes like "minLen:3O" or "requried"
+// don't compile, e.g. valdn.Field("age").Required().Int().Between(18, 99).
+// It's compiled to Rules by Build, and Rules are converted to it by FromRules, so both styles interoperate.
+type FieldRules struct {
+	name   string
+	rules  []string
+	nested Rules
+}
+
+// Field returns FieldRules of the field named name, name is a dotted path like Rules keys (e.g. address.city).
+func Field(name string) *FieldRules {
+	return &FieldRules{name: name, rules: []string{}}
+}
+
+// Each returns FieldRules of the items of the field named name (name.*), e.g. valdn.Each("tags").MaxLen(20).
+// Each("") returns FieldRules of the direct nested fields of the root (*).
+func Each(name string) *FieldRules {
+	if name == "" {
+		return Field("*")
+	}
+	return Field(name + ".*")
+}
+
+// Obj returns FieldRules of the field named name that has nested fields validated by rules,
+// rules are keyed by the names of the nested fields, e.g. valdn.Obj("address", valdn.Build(valdn.Field("city"))).
+func Obj(name string, rules Rules) *FieldRules {
+	f := Field(name)
+	f.nested = make(Rules, len(rules))
+	for k, r := range rules {
+		f.nested[name+"."+k] = append([]string{}, r...)
+	}
+	return f
+}
+
+// Alt returns FieldRules of no field, it's an alternative of rule groups,
+// e.g. valdn.Field("contact").AnyOf(valdn.Alt().Email(), valdn.Alt().PhoneNumber()).
+func Alt() *FieldRules {
+	return &FieldRules{rules: []string{}}
+}
+
+// Build compiles fields to Rules, rules of fields of the same name are appended.
+func Build(fields ...*FieldRules) Rules {
+	rules := make(Rules)
+	for _, f := range fields {
+		for k, r := range f.Rules() {
+			if _, ok := rules[k]; !ok {
+				rules[k] = []string{}
+			}
+			rules[k] = append(rules[k], r...)
+		}
+	}
+	return rules
+}
+
+// FromRules converts rules to FieldRules sorted by their names, so Build(FromRules(rules)...) equals rules.
+func FromRules(rules Rules) []*FieldRules {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]*FieldRules, len(names))
+	for i, name := range names {
+		fields[i] = &FieldRules{name: name, rules: append([]string{}, rules[name]...)}
+	}
+	return fields
+}
+
+// Name returns the name of the field.
+func (f *FieldRules) Name() string {
+	return f.name
+}
+
+// List returns the rules of the field, not including the rules of its nested fields.
+func (f *FieldRules) List() []string {
+	return append([]string{}, f.rules...)
+}
+
+// Rules returns the rules of the field and of its nested fields.
+func (f *FieldRules) Rules() Rules {
+	rules := Rules{f.name: f.List()}
+	for k, r := range f.nested {
+		rules[k] = append([]string{}, r...)
+	}
+	return rules
+}
+
+// Rule adds rule name of params to the field, params are joined by commas, e.g. Rule("between", 1, 5).
+// It's the escape hatch of rules that have no methods, e.g. rules registered by AddRule and aliases of AddAlias.
+// It panics if the rule is not registered.
+func (f *FieldRules) Rule(name string, params ...interface{}) *FieldRules {
+	if !isRegistered(name) {
+		panic("unknown rule: " + name)
+	}
+	vals := make([]string, len(params))
+	for i, p := range params {
+		vals[i] = toString(p)
+	}
+	return f.add(name, strings.Join(vals, ","))
+}
+
+// add adds rule name of value val to the field, rules that have no value are added by their names only.
+func (f *FieldRules) add(name string, val string) *FieldRules {
+	if val == "" {
+		f.rules = append(f.rules, name)
+	} else {
+		f.rules = append(f.rules, name+":"+val)
+	}
+	return f
+}
+
+// Skip skips validating the field and its nested fields.
+func (f *FieldRules) Skip() *FieldRules {
+	f.rules = append([]string{"skip"}, f.rules...)
+	return f
+}
+
+// Required adds required rule.
+func (f *FieldRules) Required() *FieldRules {
+	return f.add("required", "")
+}
+
+// Type adds type rule of the type of v, e.g. Type(time.Time{}) or Type("").
+func (f *FieldRules) Type(v interface{}) *FieldRules {
+	return f.add("type", typeName(v))
+}
+
+// NotType adds notType rule of the type of v.
+func (f *FieldRules) NotType(v interface{}) *FieldRules {
+	return f.add("notType", typeName(v))
+}
+
+// TypeIn adds typeIn rule of the types of vs.
+func (f *FieldRules) TypeIn(vs ...interface{}) *FieldRules {
+	return f.add("typeIn", joinTypeNames(vs))
+}
+
+// TypeNotIn adds typeNotIn rule of the types of vs.
+func (f *FieldRules) TypeNotIn(vs ...interface{}) *FieldRules {
+	return f.add("typeNotIn", joinTypeNames(vs))
+}
+
+// Kind adds kind rule, e.g. Kind(reflect.Slice).
+func (f *FieldRules) Kind(k reflect.Kind) *FieldRules {
+	return f.add("kind", k.String())
+}
+
+// NotKind adds notKind rule.
+func (f *FieldRules) NotKind(k reflect.Kind) *FieldRules {
+	return f.add("notKind", k.String())
+}
+
+// KindIn adds kindIn rule.
+func (f *FieldRules) KindIn(ks ...reflect.Kind) *FieldRules {
+	return f.add("kindIn", joinKinds(ks))
+}
+
+// KindNotIn adds kindNotIn rule.
+func (f *FieldRules) KindNotIn(ks ...reflect.Kind) *FieldRules {
+	return f.add("kindNotIn", joinKinds(ks))
+}
+
+// Equal adds equal rule.
+func (f *FieldRules) Equal(v interface{}) *FieldRules {
+	return f.add("equal", toString(v))
+}
+
+// Int adds int rule.
+func (f *FieldRules) Int() *FieldRules {
+	return f.add("int", "")
+}
+
+// Uint adds uint rule.
+func (f *FieldRules) Uint() *FieldRules {
+	return f.add("uint", "")
+}
+
+// Complex adds complex rule.
+func (f *FieldRules) Complex() *FieldRules {
+	return f.add("complex", "")
+}
+
+// Float adds float rule.
+func (f *FieldRules) Float() *FieldRules {
+	return f.add("float", "")
+}
+
+// UFloat adds ufloat rule.
+func (f *FieldRules) UFloat() *FieldRules {
+	return f.add("ufloat", "")
+}
+
+// Numeric adds numeric rule.
+func (f *FieldRules) Numeric() *FieldRules {
+	return f.add("numeric", "")
+}
+
+// Bool adds bool rule.
+func (f *FieldRules) Bool() *FieldRules {
+	return f.add("bool", "")
+}
+
+// Between adds between rule, e.g. Between(18, 99), use BetweenExact for bounds a float64 can't hold exactly.
+// It panics if min or max is NaN or infinite.
+func (f *FieldRules) Between(min float64, max float64) *FieldRules {
+	return f.BetweenExact(floatNumber(min), floatNumber(max))
+}
+
+// Min adds min rule, e.g. Min(0.5), use MinExact for bounds a float64 can't hold exactly.
+// It panics if min is NaN or infinite.
+func (f *FieldRules) Min(min float64) *FieldRules {
+	return f.MinExact(floatNumber(min))
+}
+
+// Max adds max rule, e.g. Max(100), use MaxExact for bounds a float64 can't hold exactly.
+// It panics if max is NaN or infinite.
+func (f *FieldRules) Max(max float64) *FieldRules {
+	return f.MaxExact(floatNumber(max))
+}
+
+// BetweenExact adds between rule of exact bounds, e.g. BetweenExact("0", "18446744073709551615").
+// It panics if min or max is not an integer or a float.
+func (f *FieldRules) BetweenExact(min json.Number, max json.Number) *FieldRules {
+	return f.add("between", numberValue("between", min)+","+numberValue("between", max))
+}
+
+// MinExact adds min rule of an exact bound, e.g. MinExact("-9007199254740993").
+// It panics if min is not an integer or a float.
+func (f *FieldRules) MinExact(min json.Number) *FieldRules {
+	return f.add("min", numberValue("min", min))
+}
+
+// MaxExact adds max rule of an exact bound, e.g. MaxExact("9007199254740993").
+// It panics if max is not an integer or a float.
+func (f *FieldRules) MaxExact(max json.Number) *FieldRules {
+	return f.add("max", numberValue("max", max))
+}
+
+// In adds in rule, values must not have commas.
+func (f *FieldRules) In(values ...string) *FieldRules {
+	return f.add("in", joinValues("in", values, ","))
+}
+
+// NotIn adds notIn rule, values must not have commas.
+func (f *FieldRules) NotIn(values ...string) *FieldRules {
+	return f.add("notIn", joinValues("notIn", values, ","))
+}
+
+// Len adds len rule.
+func (f *FieldRules) Len(n int) *FieldRules {
+	return f.add("len", strconv.Itoa(n))
+}
+
+// MinLen adds minLen rule.
+func (f *FieldRules) MinLen(n int) *FieldRules {
+	return f.add("minLen", strconv.Itoa(n))
+}
+
+// MaxLen adds maxLen rule.
+func (f *FieldRules) MaxLen(n int) *FieldRules {
+	return f.add("maxLen", strconv.Itoa(n))
+}
+
+// LenBetween adds lenBetween rule.
+func (f *FieldRules) LenBetween(min int, max int) *FieldRules {
+	return f.add("lenBetween", strconv.Itoa(min)+","+strconv.Itoa(max))
+}
+
+// LenIn adds lenIn rule.
+func (f *FieldRules) LenIn(ns ...int) *FieldRules {
+	return f.add("lenIn", joinInts(ns))
+}
+
+// LenNotIn adds lenNotIn rule.
+func (f *FieldRules) LenNotIn(ns ...int) *FieldRules {
+	return f.add("lenNotIn", joinInts(ns))
+}
+
+// Regex adds regex rule.
+// It panics if pattern is not a valid regular expression.
+func (f *FieldRules) Regex(pattern string) *FieldRules {
+	regexp.MustCompile(pattern)
+	return f.add("regex", pattern)
+}
+
+// NotRegex adds notRegex rule.
+// It panics if pattern is not a valid regular expression.
+func (f *FieldRules) NotRegex(pattern string) *FieldRules {
+	regexp.MustCompile(pattern)
+	return f.add("notRegex", pattern)
+}
+
+// Email adds email rule.
+func (f *FieldRules) Email() *FieldRules {
+	return f.add("email", "")
+}
+
+// JSON adds json rule.
+func (f *FieldRules) JSON() *FieldRules {
+	return f.add("json", "")
+}
+
+// IPv4 adds ipv4 rule.
+func (f *FieldRules) IPv4() *FieldRules {
+	return f.add("ipv4", "")
+}
+
+// IPv6 adds ipv6 rule.
+func (f *FieldRules) IPv6() *FieldRules {
+	return f.add("ipv6", "")
+}
+
+// IP adds ip rule.
+func (f *FieldRules) IP() *FieldRules {
+	return f.add("ip", "")
+}
+
+// MAC adds mac rule.
+func (f *FieldRules) MAC() *FieldRules {
+	return f.add("mac", "")
+}
+
+// URL adds url rule.
+func (f *FieldRules) URL() *FieldRules {
+	return f.add("url", "")
+}
+
+// Time adds time rule.
+func (f *FieldRules) Time() *FieldRules {
+	return f.add("time", "")
+}
+
+// TimeFormat adds timeFormat rule, e.g. TimeFormat(time.RFC3339).
+func (f *FieldRules) TimeFormat(layout string) *FieldRules {
+	return f.add("timeFormat", layout)
+}
+
+// TimeFormatIn adds timeFormatIn rule, layouts must not have [].
+func (f *FieldRules) TimeFormatIn(layouts ...string) *FieldRules {
+	return f.add("timeFormatIn", joinValues("timeFormatIn", layouts, "[]"))
+}
+
+// TimeFormatNotIn adds timeFormatNotIn rule, layouts must not have [].
+func (f *FieldRules) TimeFormatNotIn(layouts ...string) *FieldRules {
+	return f.add("timeFormatNotIn", joinValues("timeFormatNotIn", layouts, "[]"))
+}
+
+// File adds file rule.
+func (f *FieldRules) File() *FieldRules {
+	return f.add("file", "")
+}
+
+// Size adds size rule of size bytes.
+func (f *FieldRules) Size(size int64) *FieldRules {
+	return f.add("size", strconv.FormatInt(size, 10))
+}
+
+// SizeMin adds sizeMin rule of size bytes.
+func (f *FieldRules) SizeMin(size int64) *FieldRules {
+	return f.add("sizeMin", strconv.FormatInt(size, 10))
+}
+
+// SizeMax adds sizeMax rule of size bytes.
+func (f *FieldRules) SizeMax(size int64) *FieldRules {
+	return f.add("sizeMax", strconv.FormatInt(size, 10))
+}
+
+// SizeBetween adds sizeBetween rule of min and max bytes.
+func (f *FieldRules) SizeBetween(min int64, max int64) *FieldRules {
+	return f.add("sizeBetween", strconv.FormatInt(min, 10)+","+strconv.FormatInt(max, 10))
+}
+
+// Ext adds ext rule.
+func (f *FieldRules) Ext(ext string) *FieldRules {
+	return f.add("ext", ext)
+}
+
+// NotExt adds notExt rule.
+func (f *FieldRules) NotExt(ext string) *FieldRules {
+	return f.add("notExt", ext)
+}
+
+// ExtIn adds extIn rule, exts must not have commas.
+func (f *FieldRules) ExtIn(exts ...string) *FieldRules {
+	return f.add("extIn", joinValues("extIn", exts, ","))
+}
+
+// ExtNotIn adds extNotIn rule, exts must not have commas.
+func (f *FieldRules) ExtNotIn(exts ...string) *FieldRules {
+	return f.add("extNotIn", joinValues("extNotIn", exts, ","))
+}
+
+// MIME adds mime rule, e.g. MIME("image/*").
+func (f *FieldRules) MIME(mediaType string) *FieldRules {
+	return f.add("mime", mediaType)
+}
+
+// MIMEIn adds mimeIn rule, media types must not have commas.
+func (f *FieldRules) MIMEIn(mediaTypes ...string) *FieldRules {
+	return f.add("mimeIn", joinValues("mimeIn", mediaTypes, ","))
+}
+
+// UUID adds uuid rule.
+func (f *FieldRules) UUID() *FieldRules {
+	return f.add("uuid", "")
+}
+
+// PhoneNumber adds phoneNumber rule.
+func (f *FieldRules) PhoneNumber() *FieldRules {
+	return f.add("phoneNumber", "")
+}
+
+// Source adds source rule, e.g. Source("body"), see ValidateRequest.
+func (f *FieldRules) Source(sources ...string) *FieldRules {
+	return f.add("source", joinValues("source", sources, ","))
+}
+
+// AnyOf adds anyOf rule of alts, the field must pass the rules of at least one of them.
+func (f *FieldRules) AnyOf(alts ...*FieldRules) *FieldRules {
+	return f.add(groupRule("anyOf", alts))
+}
+
+// OneOf adds oneOf rule of alts, the field must pass the rules of exactly one of them.
+func (f *FieldRules) OneOf(alts ...*FieldRules) *FieldRules {
+	return f.add(groupRule("oneOf", alts))
+}
+
+// Not adds not rule of alt, the field must not pass all the rules of alt, e.g. Not(Alt().In("a", "b")).
+func (f *FieldRules) Not(alt *FieldRules) *FieldRules {
+	return f.add(groupRule("not", []*FieldRules{alt}))
+}
+
+// All adds all rule of alts, the field must pass the rules of all of them.
+func (f *FieldRules) All(alts ...*FieldRules) *FieldRules {
+	return f.add(groupRule("all", alts))
+}
+
+// typeName returns the name of the type of v that type rules compare, structs are named without their packages.
+func typeName(v interface{}) string {
+	if t := reflect.TypeOf(v); t.Kind() == reflect.Struct {
+		return t.Name()
+	}
+	return toString(reflect.TypeOf(v))
+}
+
+// joinTypeNames joins the names of the types of vs by commas.
+func joinTypeNames(vs []interface{}) string {
+	names := make([]string, len(vs))
+	for i, v := range vs {
+		names[i] = typeName(v)
+	}
+	return strings.Join(names, ",")
+}
+
+// joinKinds joins the names of ks by commas.
+func joinKinds(ks []reflect.Kind) string {
+	names := make([]string, len(ks))
+	for i, k := range ks {
+		names[i] = k.String()
+	}
+	return strings.Join(names, ",")
+}
+
+// joinInts joins ns by commas.
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// joinValues joins values of rule by sep.
+// It panics if a value has sep, since it can't be told apart from the separator.
+func joinValues(rule string, values []string, sep string) string {
+	for _, v := range values {
+		if strings.Contains(v, sep) {
+			panic(fmt.Errorf("%v: %q must not have %q", rule, v, sep))
+		}
+	}
+	return strings.Join(values, sep)
+}
+
+// floatNumber formats n without exponent and trailing zeros, e.g. 18 and 0.5.
+func floatNumber(n float64) json.Number {
+	return json.Number(strconv.FormatFloat(n, 'f', -1, 64))
+}
+
+// numberValue returns n as the value of rule.
+// It panics if n is not an integer or a float.
+func numberValue(rule string, n json.Number) string {
+	if _, err := stringToRat(string(n)); err != nil {
+		panic(fmt.Errorf("%v: %q must be an integer or a float", rule, n))
+	}
+	return string(n)
+}
+
+// groupRule returns rule group name of the rules of alts, alternatives that have more than one rule are grouped by
+// all rule, e.g. anyOf(uuid; all(int; min:1)). Rules are escaped by escapeGroupRule.
+// It panics if an alternative has no rules, or if the group is not valid (see checkGroup).
+func groupRule(name string, alts []*FieldRules) (string, string) {
+	rules := make([]string, len(alts))
+	for i, alt := range alts {
+		switch len(alt.rules) {
+		case 0:
+			panic(fmt.Errorf("%v: alternative %v has no rules", name, i))
+		case 1:
+			rules[i] = escapeGroupRule(alt.rules[0])
+		default:
+			all := make([]string, len(alt.rules))
+			for j, r := range alt.rules {
+				all[j] = escapeGroupRule(r)
+			}
+			rules[i] = "all(" + strings.Join(all, "; ") + ")"
+		}
+	}
+	group := strings.Join(rules, "; ")
+	checkGroup(group)
+	return name + "(" + group + ")", ""
+}
@@ -0,0 +1,215 @@
+package valdn
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_FieldRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     func() *FieldRules
+		want      Rules
+		wantPanic bool
+	}{
+		{
+			name:  "test Field",
+			field: func() *FieldRules { return Field("age").Required().Int().Between(18, 99) },
+			want:  Rules{"age": {"required", "int", "between:18,99"}},
+		},
+		{
+			name:  "test Field with float values",
+			field: func() *FieldRules { return Field("price").Numeric().Min(0.5).Max(1e6) },
+			want:  Rules{"price": {"numeric", "min:0.5", "max:1000000"}},
+		},
+		{
+			name: "test Field with kinds and types",
+			field: func() *FieldRules {
+				return Field("v").Kind(reflect.Slice).NotKind(reflect.Map).KindIn(reflect.Int, reflect.String).
+					KindNotIn(reflect.Bool).Type(time.Time{}).NotType("").TypeIn(0, int64(0)).TypeNotIn(false)
+			},
+			want: Rules{"v": {"kind:slice", "notKind:map", "kindIn:int,string", "kindNotIn:bool", "type:Time",
+				"notType:string", "typeIn:int,int64", "typeNotIn:bool"}},
+		},
+		{
+			name: "test Field with lengths and values",
+			field: func() *FieldRules {
+				return Field("code").Len(4).MinLen(1).MaxLen(9).LenBetween(1, 9).LenIn(2, 4).LenNotIn(3).
+					In("a", "b").NotIn("c").Equal(7).Regex("^[a-z]+$").NotRegex("x")
+			},
+			want: Rules{"code": {"len:4", "minLen:1", "maxLen:9", "lenBetween:1,9", "lenIn:2,4", "lenNotIn:3",
+				"in:a,b", "notIn:c", "equal:7", "regex:^[a-z]+$", "notRegex:x"}},
+		},
+		{
+			name: "test Field with formats",
+			field: func() *FieldRules {
+				return Field("f").Uint().Complex().Float().UFloat().Bool().Email().JSON().IPv4().IPv6().IP().MAC().
+					URL().UUID().PhoneNumber().Time().TimeFormat(time.DateOnly).
+					TimeFormatIn(time.DateOnly, time.Kitchen).TimeFormatNotIn(time.RFC3339)
+			},
+			want: Rules{"f": {"uint", "complex", "float", "ufloat", "bool", "email", "json", "ipv4", "ipv6", "ip",
+				"mac", "url", "uuid", "phoneNumber", "time", "timeFormat:2006-01-02",
+				"timeFormatIn:2006-01-02[]3:04PM", "timeFormatNotIn:2006-01-02T15:04:05Z07:00"}},
+		},
+		{
+			name: "test Field with files",
+			field: func() *FieldRules {
+				return Field("avatar").File().Size(10).SizeMin(1).SizeMax(20).SizeBetween(1, 20).Ext("png").
+					NotExt("exe").ExtIn("png", "jpg").ExtNotIn("gif").MIME("image/*").MIMEIn("image/png", "image/jpeg").
+					Source("body")
+			},
+			want: Rules{"avatar": {"file", "size:10", "sizeMin:1", "sizeMax:20", "sizeBetween:1,20", "ext:png",
+				"notExt:exe", "extIn:png,jpg", "extNotIn:gif", "mime:image/*", "mimeIn:image/png,image/jpeg",
+				"source:body"}},
+		},
+		{
+			name:  "test Field with skip",
+			field: func() *FieldRules { return Field("meta").Kind(reflect.Map).Skip() },
+			want:  Rules{"meta": {"skip", "kind:map"}},
+		},
+		{
+			name:  "test Field with rule",
+			field: func() *FieldRules { return Field("n").Rule("between", 1, 5.5).Rule("email") },
+			want:  Rules{"n": {"between:1,5.5", "email"}},
+		},
+		{
+			name:  "test Each",
+			field: func() *FieldRules { return Each("tags").Kind(reflect.String).MaxLen(20) },
+			want:  Rules{"tags.*": {"kind:string", "maxLen:20"}},
+		},
+		{
+			name:  "test Each of root",
+			field: func() *FieldRules { return Each("").Required() },
+			want:  Rules{"*": {"required"}},
+		},
+		{
+			name: "test Obj",
+			field: func() *FieldRules {
+				return Obj("address", Build(Field("city").Required(), Each("lines").MaxLen(40))).Required()
+			},
+			want: Rules{"address": {"required"}, "address.city": {"required"}, "address.lines.*": {"maxLen:40"}},
+		},
+		{
+			name:      "test Field with unknown rule",
+			field:     func() *FieldRules { return Field("n").Rule("requried") },
+			wantPanic: true,
+		},
+		{
+			name:      "test Field with invalid regex",
+			field:     func() *FieldRules { return Field("n").Regex("[") },
+			wantPanic: true,
+		},
+		{
+			name:      "test Field with value of separator",
+			field:     func() *FieldRules { return Field("n").In("a,b") },
+			wantPanic: true,
+		},
+		{
+			name: "test Field with rule groups",
+			field: func() *FieldRules {
+				return Field("id").AnyOf(Alt().UUID(), Alt().Int().Min(1)).OneOf(Alt().Len(2), Alt().Len(3)).
+					Not(Alt().In("0", "-1")).All(Alt().Required(), Alt().AnyOf(Alt().Int(), Alt().Bool()))
+			},
+			want: Rules{"id": {"anyOf(uuid; all(int; min:1))", "oneOf(len:2; len:3)", "not(in:0,-1)",
+				"all(required; anyOf(int; bool))"}},
+		},
+		{
+			name:      "test Field with rule group of alternative that has no rules",
+			field:     func() *FieldRules { return Field("id").AnyOf(Alt().UUID(), Alt()) },
+			wantPanic: true,
+		},
+		{
+			name: "test Field with exact number",
+			field: func() *FieldRules {
+				return Field("id").MinExact("-9007199254740993").MaxExact("9007199254740993").
+					BetweenExact("0", "18446744073709551615")
+			},
+			want: Rules{"id": {"min:-9007199254740993", "max:9007199254740993", "between:0,18446744073709551615"}},
+		},
+		{
+			name:      "test Field with invalid exact number",
+			field:     func() *FieldRules { return Field("n").MinExact("1O") },
+			wantPanic: true,
+		},
+		{
+			name:      "test Field with NaN number",
+			field:     func() *FieldRules { return Field("n").Max(math.NaN()) },
+			wantPanic: true,
+		},
+		{
+			name:  "test Field with rule group of escaped values",
+			field: func() *FieldRules { return Field("code").AnyOf(Alt().Regex(`^(a|b);\d$`), Alt().Int()) },
+			want:  Rules{"code": {`anyOf(regex:^\(a|b\)\;\\d$; int)`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("FieldRules panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := tt.field().Rules(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldRules.Rules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Build(t *testing.T) {
+	got := Build(
+		Field("name").Required().MinLen(3),
+		Field("age").Int(),
+		Field("age").Between(18, 99),
+		Obj("address", Rules{"city": {"required"}}),
+	)
+	want := Rules{
+		"name":         {"required", "minLen:3"},
+		"age":          {"int", "between:18,99"},
+		"address":      {},
+		"address.city": {"required"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build() = %v, want %v", got, want)
+	}
+	if errs := ValidateCollection(map[string]interface{}{"name": "Na", "age": 17}, got); len(errs) != 3 {
+		t.Errorf("ValidateCollection() by built rules = %v, want 3 errors", errs)
+	}
+}
+
+func Test_Build_values(t *testing.T) {
+	rules := Build(Field("at").TimeFormat(time.RFC3339), Field("id").MaxExact("9007199254740993"),
+		Field("code").AnyOf(Alt().Regex(`^(a|b);\d$`), Alt().Int()))
+	valid := map[string]interface{}{"at": "2021-03-04T05:06:07Z", "id": int64(9007199254740993), "code": "b;1"}
+	if errs := ValidateCollection(valid, rules); len(errs) != 0 {
+		t.Errorf("ValidateCollection() by built rules = %v, want no errors", errs)
+	}
+	invalid := map[string]interface{}{"at": "2021-03-04T05", "id": int64(9007199254740994), "code": "c;1"}
+	if errs := ValidateCollection(invalid, rules); len(errs) != 3 {
+		t.Errorf("ValidateCollection() by built rules = %v, want 3 errors", errs)
+	}
+}
+
+func Test_FromRules(t *testing.T) {
+	rules := Rules{"b": {"required"}, "a.*": {"int", "min:1"}, "$": {"kind:map"}}
+	fields := FromRules(rules)
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name())
+	}
+	if want := []string{"$", "a.*", "b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("FromRules() names = %v, want %v", names, want)
+	}
+	if got := fields[1].List(); !reflect.DeepEqual(got, []string{"int", "min:1"}) {
+		t.Errorf("FromRules() list = %v, want [int min:1]", got)
+	}
+	if got := Build(FromRules(rules)...); !reflect.DeepEqual(got, rules) {
+		t.Errorf("Build(FromRules()) = %v, want %v", got, rules)
+	}
+	fields[2].Int()
+	if !reflect.DeepEqual(rules["b"], []string{"required"}) {
+		t.Errorf("FromRules() shares the rules of b: %v", rules["b"])
+	}
+}
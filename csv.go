@@ -0,0 +1,181 @@
+package valdn
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrMissingColumn is wrapped by CSVError when the header of a CSV document lacks a column that has required rule.
+	ErrMissingColumn = errors.New("missing column")
+	// ErrUnknownColumn is wrapped by CSVError when the header of a CSV document has a column that has no rules.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrDuplicateColumn is wrapped by CSVError when the header of a CSV document has the same column more than once.
+	ErrDuplicateColumn = errors.New("duplicate column")
+	// ErrTooManyRows is returned when a CSV document has more rows than Limits.MaxRows.
+	ErrTooManyRows = errors.New("too many rows")
+)
+
+// CSVError is the error of a CSV document whose header is rejected, see ValidateCSV.
+// Row is the row of the rejected cell (the header is row 1), Column is its column name, and Letter is its column letter,
+// it's empty if the header lacks the column.
+type CSVError struct {
+	Row    int
+	Column string
+	Letter string
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("%v %v", e.Err, csvCellName(e.Column, e.Letter, e.Row))
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateCSV reads CSV from r and validates it by rules row by row, and returns Errors.
+// The first row is the header, its column names are the rule keys of the cells of the next rows, * applies rules to
+// all columns. Cells are converted by the types their rules declare like ValidateRequest (see WithLossless),
+// and empty cells are considered absent.
+// Errors are keyed by column name and cell reference, e.g. price (C3) is the price cell of column C of row 3,
+// or by the path of the cell (row number then column name, e.g. 3.price) if a PathFormatter is set by WithPathFormat.
+// If an error is found it will not check the rest of the field's rules and continue to the next field.
+// It panics with CSVError if the header lacks a column that has required rule, has the same column more than once,
+// or has a column that has no rules when WithDisallowUnknownFields is used.
+// It panics if r is not valid CSV, can't be read, or exceeds the limits set using WithLimits.
+// It panics if one of the rules is not registered.
+func ValidateCSV(r io.Reader, rules Rules, opts ...Option) Errors {
+	c := newConfig(opts)
+	cr := csv.NewReader(c.limits.limitReader(r))
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		panic(fmt.Errorf("%w: document has no header", io.ErrUnexpectedEOF))
+	}
+	if err != nil {
+		panic(err)
+	}
+	// the header is copied, since records are reused
+	header = append([]string{}, header...)
+	// spreadsheets may start UTF-8 files with a byte order mark
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	letters := checkCSVHeader(header, rules, c)
+
+	errs := make(Errors)
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return errs
+		}
+		if err != nil {
+			panic(err)
+		}
+		if l := c.limits; l.MaxRows > 0 && row-1 > l.MaxRows {
+			panic(fmt.Errorf("%w: more than %v", ErrTooManyRows, l.MaxRows))
+		}
+		for name, err := range validateCSVRow(header, record, row, letters, rules, c) {
+			errs[name] = err
+		}
+	}
+}
+
+// checkCSVHeader checks the columns of header by rules, and returns the letters of the columns by their names.
+func checkCSVHeader(header []string, rules Rules, c *config) map[string]string {
+	letters := make(map[string]string, len(header))
+	v := createNewValidation(rules)
+	for i, column := range header {
+		letter := columnLetter(i)
+		if _, ok := letters[column]; ok {
+			panic(&CSVError{Row: 1, Column: column, Letter: letter, Err: ErrDuplicateColumn})
+		}
+		if c.knownFields && len(v.getFieldRules(column)) == 0 {
+			panic(&CSVError{Row: 1, Column: column, Letter: letter, Err: ErrUnknownColumn})
+		}
+		letters[column] = letter
+	}
+	for name, fieldRules := range rules {
+		if _, ok := letters[name]; ok || name == RootName || strings.HasSuffix(name, "*") {
+			continue
+		}
+		if hasRule(fieldRules, "required") {
+			panic(&CSVError{Row: 1, Column: name, Err: ErrMissingColumn})
+		}
+	}
+	return letters
+}
+
+// validateCSVRow validates the cells of record at row by rules, header has their column names and letters has
+// the letters of the columns by their names.
+func validateCSVRow(header []string, record []string, row int, letters map[string]string, rules Rules, c *config) Errors {
+	cellName := func(column string) string {
+		if c.pathFormat != nil {
+			return c.pathFormat(Path{row, column})
+		}
+		return csvCellName(column, letters[column], row)
+	}
+
+	v := createNewValidation(rules)
+	m := make(map[string]interface{}, len(record))
+	parseErrs := make(Errors)
+	for i, cell := range record {
+		column := header[i]
+		columnRules := v.getFieldRules(column)
+		if cell == "" {
+			// empty cells are absent, but required ones are kept, since wildcard rules don't check absent fields
+			if hasRule(columnRules, "required") {
+				m[column] = cell
+			}
+			continue
+		}
+		if c.lossless {
+			m[column] = cell
+			continue
+		}
+		val, err := convertReqVal(cellName(column), cell, columnRules)
+		if err != nil {
+			parseErrs[column] = err.Error()
+			continue
+		}
+		m[column] = val
+	}
+
+	// cells are named by their columns, so they're formatted as cells of row
+	rc := *c
+	rc.pathFormat = func(p Path) string { return cellName(p.String()) }
+	return validateParsed(m, rules, parseErrs, &rc)
+}
+
+// csvCellName returns the name of the cell of column at row, e.g. price (C3), or price (row 3) if it has no letter.
+func csvCellName(column string, letter string, row int) string {
+	if letter == "" {
+		return column + " (row " + strconv.Itoa(row) + ")"
+	}
+	return column + " (" + letter + strconv.Itoa(row) + ")"
+}
+
+// columnLetter returns the letter of the column at index i (starting at 0) as spreadsheets do: A, B, ..., Z, AA, AB...
+func columnLetter(i int) string {
+	letter := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		letter = string(rune('A'+(i-1)%26)) + letter
+	}
+	return letter
+}
+
+// hasRule reports weather rules has the rule named name.
+func hasRule(rules []string, name string) bool {
+	for _, r := range expandAliases(rules) {
+		if rName, _ := splitRuleNameAndRuleValue(r); rName == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package valdn
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_columnLetter(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{i: 0, want: "A"},
+		{i: 25, want: "Z"},
+		{i: 26, want: "AA"},
+		{i: 51, want: "AZ"},
+		{i: 52, want: "BA"},
+		{i: 701, want: "ZZ"},
+		{i: 702, want: "AAA"},
+	}
+	for _, tt := range tests {
+		t.Run("test columnLetter with "+tt.want, func(t *testing.T) {
+			if got := columnLetter(tt.i); got != tt.want {
+				t.Errorf("columnLetter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_csvCellName(t *testing.T) {
+	if got := csvCellName("price", "C", 3); got != "price (C3)" {
+		t.Errorf("csvCellName() = %v, want price (C3)", got)
+	}
+	if got := csvCellName("price", "", 1); got != "price (row 1)" {
+		t.Errorf("csvCellName() = %v, want price (row 1)", got)
+	}
+}
+
+func Test_ValidateCSV(t *testing.T) {
+	prices := "sku,name,price,currency\n" +
+		"a1,Tea,12.5,EGP\n" +
+		"b2,,-1,USD\n" +
+		"c3,Coffee,x,EUR\n"
+	rules := Rules{
+		"sku":      {"required", "regex:^[a-z][0-9]$"},
+		"name":     {"required", "maxLen:20"},
+		"price":    {"required", "float", "min:0"},
+		"currency": {"in:EGP,USD"},
+	}
+	tests := []struct {
+		name    string
+		csv     string
+		rules   Rules
+		opts    []Option
+		want    Errors
+		wantErr error
+	}{
+		{
+			name:  "test ValidateCSV",
+			csv:   prices,
+			rules: rules,
+			want: Errors{
+				"name (B3)":     GetErrMsg("required", "", "name (B3)", ""),
+				"price (C3)":    GetErrMsg("min", "0", "price (C3)", -1),
+				"price (C4)":    GetErrMsg("float", "", "price (C4)", "x"),
+				"currency (D4)": GetErrMsg("in", "EGP,USD", "currency (D4)", "EUR"),
+			},
+		},
+		{
+			name:  "test ValidateCSV with byte order mark and quoted cells",
+			csv:   "\ufeffsku,name\r\na1,\"Tea, green\"\r\n",
+			rules: Rules{"sku": {"required"}, "name": {"required", "maxLen:5"}},
+			want:  Errors{"name (B2)": GetErrMsg("maxLen", "5", "name (B2)", "Tea, green")},
+		},
+		{
+			name:  "test ValidateCSV with wildcard rules",
+			csv:   "a,b\n1,\n",
+			rules: Rules{"*": {"required"}},
+			want:  Errors{"b (B2)": GetErrMsg("required", "", "b (B2)", "")},
+		},
+		{
+			name:  "test ValidateCSV with path format",
+			csv:   prices,
+			rules: Rules{"price": {"float", "min:0"}},
+			opts:  []Option{WithPathFormat(JSONPointerPath)},
+			want: Errors{
+				"/3/price": GetErrMsg("min", "0", "/3/price", -1),
+				"/4/price": GetErrMsg("float", "", "/4/price", "x"),
+			},
+		},
+		{
+			name:  "test ValidateCSV with lossless values",
+			csv:   prices,
+			rules: Rules{"price": {"kind:string"}},
+			opts:  []Option{WithLossless()},
+			want:  Errors{},
+		},
+		{
+			name:    "test ValidateCSV with missing column",
+			csv:     "sku,name\na1,Tea\n",
+			rules:   rules,
+			wantErr: ErrMissingColumn,
+		},
+		{
+			name:    "test ValidateCSV with unknown column",
+			csv:     prices,
+			rules:   Rules{"sku": {"required"}, "name": {"required"}, "price": {"float"}},
+			opts:    []Option{WithDisallowUnknownFields()},
+			wantErr: ErrUnknownColumn,
+		},
+		{
+			name:    "test ValidateCSV with duplicate column",
+			csv:     "sku,sku\na1,b2\n",
+			rules:   Rules{},
+			wantErr: ErrDuplicateColumn,
+		},
+		{
+			name:    "test ValidateCSV with too many rows",
+			csv:     prices,
+			rules:   rules,
+			opts:    []Option{WithLimits(Limits{MaxRows: 2})},
+			wantErr: ErrTooManyRows,
+		},
+		{
+			name:    "test ValidateCSV with too large document",
+			csv:     prices,
+			rules:   rules,
+			opts:    []Option{WithLimits(Limits{MaxBodyBytes: 20})},
+			wantErr: ErrBodyTooLarge,
+		},
+		{
+			name:    "test ValidateCSV with empty document",
+			csv:     "",
+			rules:   rules,
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "test ValidateCSV with wrong number of cells",
+			csv:     "a,b\n1,2,3\n",
+			rules:   Rules{},
+			wantErr: csv.ErrFieldCount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				e := recover()
+				err, _ := e.(error)
+				if (e != nil || tt.wantErr != nil) && !errors.Is(err, tt.wantErr) {
+					t.Errorf("ValidateCSV() panic = %v, wantErr %v", e, tt.wantErr)
+				}
+			}()
+			if got := ValidateCSV(strings.NewReader(tt.csv), tt.rules, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateCSV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_CSVError(t *testing.T) {
+	err := &CSVError{Row: 1, Column: "extra", Letter: "E", Err: ErrUnknownColumn}
+	if got := err.Error(); got != "unknown column extra (E1)" {
+		t.Errorf("CSVError.Error() = %v, want unknown column extra (E1)", got)
+	}
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("CSVError doesn't wrap %v", ErrUnknownColumn)
+	}
+}
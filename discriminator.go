@@ -0,0 +1,130 @@
+package valdn
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// discriminator selects the rules of the objects named name by the value of their field.
+type discriminator struct {
+	name  string
+	field string
+	cases map[string]Rules
+}
+
+// WithDiscriminator validates the objects (maps and structs) named name by the rules of cases selected by the value of
+// their field, e.g. WithDiscriminator("payment", "type", map[string]Rules{"card": {"number": {"required"}}}).
+// name is a dotted path like Rules keys, * matches any field or index (e.g. items.*), and RootName is the root.
+// Rules of cases are keyed by the names of the fields of the object, and added to the rules they already have.
+// Objects that don't have the field have the error of required rule, and objects whose field has a value that has no
+// case have the error of in rule of the values of cases.
+// Fields of all the cases are known by WithDisallowUnknownFields.
+// It panics if field is empty.
+func WithDiscriminator(name string, field string, cases map[string]Rules) Option {
+	if field == "" {
+		panic("WithDiscriminator: field must not be empty")
+	}
+	if name == RootName {
+		name = ""
+	}
+	d := discriminator{name: name, field: field, cases: cases}
+	return func(c *config) {
+		c.discriminators = append(c.discriminators, d)
+	}
+}
+
+// matches reports weather the object at p is named by the name of d.
+func (d discriminator) matches(p Path) bool {
+	if d.name == "" {
+		return len(p) == 0
+	}
+	segments := strings.Split(d.name, ".")
+	if len(segments) != len(p) {
+		return false
+	}
+	for i, s := range segments {
+		if s != "*" && s != toString(p[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// values returns the values of the cases of d sorted.
+func (d discriminator) values() []string {
+	values := make([]string, 0, len(d.cases))
+	for val := range d.cases {
+		values = append(values, val)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// addDiscriminatorRules adds the rules of the cases selected by the discriminators of object val at p.
+// Fields that have no rules of their own have the rules of their wildcards before the rules of the case.
+func (v *validation) addDiscriminatorRules(p Path, parName string, val interface{}) {
+	for _, d := range v.discriminators {
+		if !d.matches(p) {
+			continue
+		}
+		name := v.errorName(p.join(d.field), p.childName(parName, d.field))
+		fVal, ok := objectField(val, d.field)
+		if !ok {
+			v.addError(name, errors.New(GetErrMsg("required", "", name, fVal)))
+			continue
+		}
+		caseRules, ok := d.cases[toString(fVal)]
+		if !ok {
+			v.addError(name, errors.New(GetErrMsg("in", strings.Join(d.values(), ","), name, fVal)))
+			continue
+		}
+		v.addRules(p, caseRules)
+	}
+}
+
+// objectField returns the value of field of map or struct val, and reports weather val has the field.
+func objectField(val interface{}, field string) (interface{}, bool) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Map:
+		fVal, ok := convertInterfaceToMap(val)[field]
+		return fVal, ok
+	case reflect.Struct:
+		f := rv.FieldByName(field)
+		if !f.IsValid() || !f.CanInterface() {
+			return nil, false
+		}
+		return f.Interface(), true
+	}
+	return nil, false
+}
+
+// discriminatorRules returns rules with the rules of all the cases of discriminators, and the rules of their fields,
+// so the fields of the cases (and of the schemas of their schema rules) are known by WithDisallowUnknownFields.
+func discriminatorRules(rules Rules, discriminators []discriminator) Rules {
+	if len(discriminators) == 0 {
+		return rules
+	}
+	rules = copyRules(rules)
+	add := func(name string, r []string) {
+		rules[name] = append(append([]string{}, rules[name]...), r...)
+	}
+	for _, d := range discriminators {
+		add(joinName(d.name, d.field), nil)
+		for _, caseRules := range d.cases {
+			for k, r := range caseRules {
+				add(joinName(d.name, k), r)
+			}
+		}
+	}
+	return rules
+}
+
+// withDiscriminators sets the discriminators of the config to discriminators.
+func withDiscriminators(discriminators []discriminator) Option {
+	return func(c *config) {
+		c.discriminators = discriminators
+	}
+}
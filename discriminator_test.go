@@ -0,0 +1,290 @@
+package valdn
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var paymentCases = map[string]Rules{
+	"card":          {"number": {"required", "len:16"}, "expiry": {"required"}},
+	"bank_transfer": {"iban": {"required"}},
+}
+
+func Test_discriminator_matches(t *testing.T) {
+	tests := []struct {
+		name string
+		d    discriminator
+		p    Path
+		want bool
+	}{
+		{
+			name: "test discriminator matches root",
+			d:    discriminator{name: ""},
+			p:    nil,
+			want: true,
+		},
+		{
+			name: "test discriminator doesn't match nested field of root",
+			d:    discriminator{name: ""},
+			p:    Path{"payment"},
+			want: false,
+		},
+		{
+			name: "test discriminator matches field",
+			d:    discriminator{name: "order.payment"},
+			p:    Path{"order", "payment"},
+			want: true,
+		},
+		{
+			name: "test discriminator matches items",
+			d:    discriminator{name: "items.*"},
+			p:    Path{"items", 3},
+			want: true,
+		},
+		{
+			name: "test discriminator doesn't match other field",
+			d:    discriminator{name: "items.*"},
+			p:    Path{"orders", 3},
+			want: false,
+		},
+		{
+			name: "test discriminator doesn't match nested field of items",
+			d:    discriminator{name: "items.*"},
+			p:    Path{"items", 3, "payment"},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.matches(tt.p); got != tt.want {
+				t.Errorf("discriminator.matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_objectField(t *testing.T) {
+	type payment struct {
+		Type string
+		iban string
+	}
+	tests := []struct {
+		name   string
+		val    interface{}
+		field  string
+		want   interface{}
+		wantOk bool
+	}{
+		{
+			name:   "test object field of map",
+			val:    map[string]interface{}{"type": "card"},
+			field:  "type",
+			want:   "card",
+			wantOk: true,
+		},
+		{
+			name:   "test object field of map that doesn't have it",
+			val:    map[string]string{"kind": "card"},
+			field:  "type",
+			wantOk: false,
+		},
+		{
+			name:   "test object field of struct",
+			val:    payment{Type: "card"},
+			field:  "Type",
+			want:   "card",
+			wantOk: true,
+		},
+		{
+			name:   "test object field of struct unexported field",
+			val:    payment{iban: "EG38"},
+			field:  "iban",
+			wantOk: false,
+		},
+		{
+			name:   "test object field of slice",
+			val:    []string{"card"},
+			field:  "0",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk := objectField(tt.val, tt.field)
+			if got != tt.want || gotOk != tt.wantOk {
+				t.Errorf("objectField() = %v, %v, want %v, %v", got, gotOk, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_discriminatorRules(t *testing.T) {
+	rules := Rules{"items": {"required"}, "items.*.number": {"int"}}
+	got := discriminatorRules(rules, []discriminator{{name: "items.*", field: "type", cases: paymentCases}})
+	want := Rules{
+		"items":          {"required"},
+		"items.*.type":   {},
+		"items.*.number": {"int", "required", "len:16"},
+		"items.*.expiry": {"required"},
+		"items.*.iban":   {"required"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("discriminatorRules() = %v, want %v", got, want)
+	}
+	if len(rules) != 2 {
+		t.Errorf("discriminatorRules() changed rules: %v", rules)
+	}
+}
+
+func Test_WithDiscriminator(t *testing.T) {
+	type card struct {
+		Type   string
+		Number string `valdn:"required|len:16"`
+	}
+	type event struct {
+		Payment interface{}
+	}
+	structCases := map[string]Rules{"card": {}, "bank_transfer": {"IBAN": {"required"}}}
+	tests := []struct {
+		name      string
+		validate  func() Errors
+		want      Errors
+		wantPanic bool
+	}{
+		{
+			name: "test discriminator of map field",
+			validate: func() Errors {
+				val := map[string]interface{}{"payment": map[string]interface{}{"type": "card", "number": "42"}}
+				return ValidateCollection(val, Rules{}, WithDiscriminator("payment", "type", paymentCases))
+			},
+			want: Errors{
+				"payment.number": GetErrMsg("len", "16", "payment.number", "42"),
+				"payment.expiry": GetErrMsg("required", "", "payment.expiry", ""),
+			},
+		},
+		{
+			name: "test discriminator of root with rules of fields",
+			validate: func() Errors {
+				val := map[string]interface{}{"type": "bank_transfer", "iban": "EG"}
+				return ValidateCollection(val, Rules{"iban": {"minLen:4"}}, WithDiscriminator(RootName, "type", paymentCases))
+			},
+			want: Errors{"iban": GetErrMsg("minLen", "4", "iban", "EG")},
+		},
+		{
+			name: "test discriminator of items",
+			validate: func() Errors {
+				val := map[string]interface{}{"items": []interface{}{
+					map[string]interface{}{"type": "bank_transfer", "iban": "EG38"},
+					map[string]interface{}{"type": "bank_transfer"},
+					map[string]interface{}{"type": "cash"},
+					map[string]interface{}{"iban": "EG38"},
+				}}
+				return ValidateCollection(val, Rules{"items.*": {"kind:map"}}, WithDiscriminator("items.*", "type", paymentCases))
+			},
+			want: Errors{
+				"items.1.iban": GetErrMsg("required", "", "items.1.iban", ""),
+				"items.2.type": GetErrMsg("in", "bank_transfer,card", "items.2.type", "cash"),
+				"items.3.type": GetErrMsg("required", "", "items.3.type", nil),
+			},
+		},
+		{
+			name: "test discriminator of interface struct field of struct",
+			validate: func() Errors {
+				val := event{Payment: card{Type: "card", Number: "42"}}
+				return ValidateCollection(val, Rules{}, WithDiscriminator("Payment", "Type", structCases))
+			},
+			want: Errors{"Payment.Number": GetErrMsg("len", "16", "Payment.Number", "42")},
+		},
+		{
+			name: "test discriminator of interface struct field of map",
+			validate: func() Errors {
+				val := event{Payment: map[string]interface{}{"Type": "bank_transfer"}}
+				return ValidateCollection(val, Rules{}, WithDiscriminator("Payment", "Type", structCases))
+			},
+			want: Errors{"Payment.IBAN": GetErrMsg("required", "", "Payment.IBAN", "")},
+		},
+		{
+			name: "test discriminator of JSON",
+			validate: func() Errors {
+				val := `[{"type": "card", "number": "4242424242424242", "expiry": "12/30"}, {"type": "bank_transfer"}]`
+				return ValidateJSON(val, Rules{}, WithDiscriminator("*", "type", paymentCases), WithDisallowUnknownFields())
+			},
+			want: Errors{"1.iban": GetErrMsg("required", "", "1.iban", "")},
+		},
+		{
+			name: "test discriminator of JSON with unknown fields",
+			validate: func() Errors {
+				val := `{"type": "card", "number": "4242424242424242", "expiry": "12/30", "cvv": "123"}`
+				return ValidateJSON(val, Rules{}, WithDiscriminator(RootName, "type", paymentCases), WithDisallowUnknownFields())
+			},
+			wantPanic: true,
+		},
+		{
+			name: "test discriminator of JSON stream",
+			validate: func() Errors {
+				val := `{"items": [{"number": "42", "type": "card"}, {"type": "bank_transfer"}, {"type": "cash"}], "note": 1}`
+				rules := Rules{"items": {"minLen:1"}, "note": {"kind:string"}}
+				return ValidateJSONStream(strings.NewReader(val), rules, WithDiscriminator("items.*", "type", paymentCases))
+			},
+			want: Errors{
+				"items.0.number": GetErrMsg("len", "16", "items.0.number", "42"),
+				"items.0.expiry": GetErrMsg("required", "", "items.0.expiry", ""),
+				"items.1.iban":   GetErrMsg("required", "", "items.1.iban", ""),
+				"items.2.type":   GetErrMsg("in", "bank_transfer,card", "items.2.type", "cash"),
+				"note":           GetErrMsg("kind", "string", "note", 1),
+			},
+		},
+		{
+			name: "test discriminator of JSON stream with unknown fields",
+			validate: func() Errors {
+				val := `{"type": "card", "number": "4242424242424242", "expiry": "12/30", "cvv": "123"}`
+				return ValidateJSONStream(strings.NewReader(val), Rules{}, WithDiscriminator(RootName, "type", paymentCases), WithDisallowUnknownFields())
+			},
+			wantPanic: true,
+		},
+		{
+			name: "test discriminator of empty field",
+			validate: func() Errors {
+				return ValidateCollection(map[string]interface{}{}, Rules{}, WithDiscriminator(RootName, "", paymentCases))
+			},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("WithDiscriminator() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := tt.validate(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithDiscriminator() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateRequest_discriminator(t *testing.T) {
+	body := `{"event": "paid", "payment": {"type": "card", "number": "42", "expiry": "12/30"}}`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	got := ValidateRequest(r, Rules{"event": {"required"}}, WithDiscriminator("payment", "type", paymentCases))
+	want := Errors{"payment.number": GetErrMsg("len", "16", "payment.number", "42")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateRequest() = %v, want %v", got, want)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"payment": {"type": "card", "cvv": "1"}}`))
+	r.Header.Set("Content-Type", "application/json")
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrUnknownField) {
+			t.Errorf("ValidateRequest() panic = %v, want %v", err, ErrUnknownField)
+		}
+	}()
+	ValidateRequest(r, Rules{}, WithDiscriminator("payment", "type", paymentCases), WithDisallowUnknownFields())
+}
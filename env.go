@@ -0,0 +1,179 @@
+package valdn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvTagName is the struct tag ValidateEnvStruct reads the names of environment variables from.
+var EnvTagName = "env"
+
+// exit and stderr are used by MustValidateEnv, they're replaced by tests.
+var (
+	exit             = os.Exit
+	stderr io.Writer = os.Stderr
+)
+
+// ValidateEnv validates the environment variables whose names start with prefix by rules and returns Errors.
+// Rules are keyed by the names of the variables without prefix (e.g. PORT validates APP_PORT if prefix is APP_),
+// and * applies rules to all variables that start with prefix. Errors are keyed by the full names of the variables.
+// Values are converted by the types their rules declare like ValidateRequest, so int, between:1,65535 and
+// in:debug,info work on the strings of the environment. Unset and empty variables are considered absent.
+// If an error is found it will not check the rest of the field's rules and continue to the next field.
+// It panics if one of the rules is not registered.
+func ValidateEnv(prefix string, rules Rules) Errors {
+	v := createNewValidation(rules)
+	m := make(map[string]interface{})
+	parseErrs := make(Errors)
+	for _, kv := range os.Environ() {
+		name, val, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) || name == prefix || val == "" {
+			continue
+		}
+		key := strings.TrimPrefix(name, prefix)
+		converted, err := convertReqVal(name, val, v.getFieldRules(key))
+		if err != nil {
+			parseErrs[key] = err.Error()
+			continue
+		}
+		m[key] = converted
+	}
+
+	c := &config{pathFormat: func(p Path) string { return prefix + p.String() }}
+	return validateParsed(m, rules, parseErrs, c)
+}
+
+// ValidateEnvStruct reads the environment variables named by the env tags of the fields of the struct v points to,
+// prefixed by prefix, validates them by the rules of their valdn tags like ValidateEnv and returns Errors.
+// Fields of variables that are set and valid are set to their values, values are parsed by the kinds of the fields:
+// string, bool, integers, floats and time.Duration. Fields that have no env tag are ignored.
+// It panics if v is not a pointer to struct, or if a field of env tag has another kind.
+// It panics if one of the rules is not registered.
+func ValidateEnvStruct(prefix string, v interface{}) Errors {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("ValidateEnvStruct: v must be a pointer to struct got %v", reflect.TypeOf(v)))
+	}
+	val = val.Elem()
+	typ := val.Type()
+
+	rules := make(Rules)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := f.Tag.Get(EnvTagName)
+		if name == "" || !f.IsExported() {
+			continue
+		}
+		if !isEnvKind(f.Type) {
+			panic(fmt.Errorf("ValidateEnvStruct: field %v must be kind of string, bool, integer or float got %v", f.Name, f.Type))
+		}
+		if tRules := f.Tag.Get(TagName); tRules != "" {
+			rules[name] = strings.Split(tRules, TagSeparator)
+		}
+	}
+
+	errs := ValidateEnv(prefix, rules)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := f.Tag.Get(EnvTagName)
+		if name == "" || !f.IsExported() {
+			continue
+		}
+		s, ok := os.LookupEnv(prefix + name)
+		if _, failed := errs[prefix+name]; !ok || s == "" || failed {
+			continue
+		}
+		if err := setEnvField(val.Field(i), s); err != nil {
+			errs[prefix+name] = GetErrMsg("kind", f.Type.String(), prefix+name, s)
+		}
+	}
+	return errs
+}
+
+// MustValidateEnv validates the environment variables that start with prefix by rules like ValidateEnv.
+// If there are errors it prints them to stderr, one per line sorted by name, and exits with status 1,
+// so services fail fast at startup when they're misconfigured.
+// It panics if one of the rules is not registered.
+func MustValidateEnv(prefix string, rules Rules) {
+	if errs := ValidateEnv(prefix, rules); len(errs) > 0 {
+		_, _ = io.WriteString(stderr, envReport(errs))
+		exit(1)
+	}
+}
+
+// envReport returns a readable report of errs, one error per line sorted by name.
+func envReport(errs Errors) string {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid environment (%v errors):\n", len(errs))
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %v: %v\n", name, errs[name])
+	}
+	return b.String()
+}
+
+// isEnvKind reports weather fields of typ can be set from environment variables.
+func isEnvKind(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// setEnvField parses s by the kind of field f and sets f to it.
+func setEnvField(f reflect.Value, s string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			f.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	default:
+		return errors.New("unsupported kind " + f.Kind().String())
+	}
+	return nil
+}
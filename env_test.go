@@ -0,0 +1,128 @@
+package valdn
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_ValidateEnv(t *testing.T) {
+	t.Setenv("VALDN_TEST_PORT", "80800")
+	t.Setenv("VALDN_TEST_WORKERS", "four")
+	t.Setenv("VALDN_TEST_LOG_LEVEL", "info")
+	t.Setenv("VALDN_TEST_API_URL", "https://example.com")
+	t.Setenv("VALDN_TEST_EMPTY", "")
+	tests := []struct {
+		name  string
+		rules Rules
+		want  Errors
+	}{
+		{
+			name: "test ValidateEnv",
+			rules: Rules{
+				"PORT":      {"required", "int", "between:1,65535"},
+				"WORKERS":   {"int"},
+				"LOG_LEVEL": {"required", "in:debug,info"},
+				"API_URL":   {"required", "url"},
+				"EMPTY":     {"required"},
+				"MISSING":   {"required"},
+				"OPTIONAL":  {"int"},
+			},
+			want: Errors{
+				"VALDN_TEST_PORT":    GetErrMsg("between", "1,65535", "VALDN_TEST_PORT", 80800),
+				"VALDN_TEST_WORKERS": GetErrMsg("int", "", "VALDN_TEST_WORKERS", "four"),
+				"VALDN_TEST_EMPTY":   GetErrMsg("required", "", "VALDN_TEST_EMPTY", nil),
+				"VALDN_TEST_MISSING": GetErrMsg("required", "", "VALDN_TEST_MISSING", nil),
+			},
+		},
+		{
+			name:  "test ValidateEnv with wildcard rules",
+			rules: Rules{"*": {"maxLen:4"}},
+			want: Errors{
+				"VALDN_TEST_PORT":    GetErrMsg("maxLen", "4", "VALDN_TEST_PORT", "80800"),
+				"VALDN_TEST_API_URL": GetErrMsg("maxLen", "4", "VALDN_TEST_API_URL", "https://example.com"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateEnv("VALDN_TEST_", tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateEnvStruct(t *testing.T) {
+	type config struct {
+		Port     int           `env:"PORT" valdn:"required|between:1,65535"`
+		Level    string        `env:"LOG_LEVEL" valdn:"in:debug,info"`
+		Debug    bool          `env:"DEBUG"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Ratio    float32       `env:"RATIO" valdn:"ufloat"`
+		Workers  uint8         `env:"WORKERS"`
+		Host     string        `env:"HOST" valdn:"required"`
+		Internal string
+	}
+	t.Setenv("VALDN_TEST_PORT", "8080")
+	t.Setenv("VALDN_TEST_LOG_LEVEL", "trace")
+	t.Setenv("VALDN_TEST_DEBUG", "true")
+	t.Setenv("VALDN_TEST_TIMEOUT", "1m30s")
+	t.Setenv("VALDN_TEST_RATIO", "0.5")
+	t.Setenv("VALDN_TEST_WORKERS", "300")
+
+	c := config{Level: "info", Internal: "kept"}
+	got := ValidateEnvStruct("VALDN_TEST_", &c)
+	want := Errors{
+		"VALDN_TEST_LOG_LEVEL": GetErrMsg("in", "debug,info", "VALDN_TEST_LOG_LEVEL", "trace"),
+		"VALDN_TEST_WORKERS":   GetErrMsg("kind", "uint8", "VALDN_TEST_WORKERS", "300"),
+		"VALDN_TEST_HOST":      GetErrMsg("required", "", "VALDN_TEST_HOST", nil),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateEnvStruct() = %v, want %v", got, want)
+	}
+	wantConfig := config{Port: 8080, Level: "info", Debug: true, Timeout: 90 * time.Second, Ratio: 0.5, Internal: "kept"}
+	if c != wantConfig {
+		t.Errorf("ValidateEnvStruct() config = %+v, want %+v", c, wantConfig)
+	}
+
+	for _, v := range []interface{}{config{}, &struct {
+		Hosts []string `env:"HOSTS"`
+	}{}} {
+		func() {
+			defer func() {
+				if e := recover(); e == nil {
+					t.Errorf("ValidateEnvStruct(%T) didn't panic", v)
+				}
+			}()
+			ValidateEnvStruct("VALDN_TEST_", v)
+		}()
+	}
+}
+
+func Test_MustValidateEnv(t *testing.T) {
+	var out strings.Builder
+	code := -1
+	defer func(e func(int), w io.Writer) {
+		exit = e
+		stderr = w
+	}(exit, stderr)
+	exit = func(c int) { code = c }
+	stderr = &out
+
+	t.Setenv("VALDN_TEST_PORT", "x")
+	MustValidateEnv("VALDN_TEST_", Rules{"PORT": {"required"}})
+	if code != -1 || out.Len() != 0 {
+		t.Errorf("MustValidateEnv() exited with %v and printed %q, want no exit", code, out.String())
+	}
+
+	MustValidateEnv("VALDN_TEST_", Rules{"PORT": {"int"}, "HOST": {"required"}})
+	want := "invalid environment (2 errors):\n" +
+		"  VALDN_TEST_HOST: " + GetErrMsg("required", "", "VALDN_TEST_HOST", nil) + "\n" +
+		"  VALDN_TEST_PORT: " + GetErrMsg("int", "", "VALDN_TEST_PORT", "x") + "\n"
+	if code != 1 || out.String() != want {
+		t.Errorf("MustValidateEnv() exited with %v and printed %q, want 1 and %q", code, out.String(), want)
+	}
+}
@@ -0,0 +1,46 @@
+package valdn
+
+import (
+	"flag"
+)
+
+// ValidateFlags validates the values of the flags defined in fs by rules and returns Errors keyed by flag name.
+// It should be called after fs is parsed. Values are typed by flag.Getter (e.g. int of fs.Int, time.Duration of
+// fs.Duration), values of flags that don't implement it and string values are converted by the types their rules
+// declare like ValidateRequest.
+// Flags that are not set explicitly have their default values, but they're considered absent by required rule,
+// so required means the flag must be set on the command line. Flags that are not set and have empty default values
+// are absent.
+// If an error is found it will not check the rest of the field's rules and continue to the next field.
+// It panics if one of the rules is not registered.
+func ValidateFlags(fs *flag.FlagSet, rules Rules) Errors {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	v := createNewValidation(rules)
+	m := make(map[string]interface{})
+	parseErrs := make(Errors)
+	fs.VisitAll(func(f *flag.Flag) {
+		fRules := v.getFieldRules(f.Name)
+		var val interface{} = f.Value.String()
+		if g, ok := f.Value.(flag.Getter); ok {
+			val = g.Get()
+		}
+		if !set[f.Name] && (hasRule(fRules, "required") || val == "") {
+			return
+		}
+		if s, ok := val.(string); ok {
+			converted, err := convertReqVal(f.Name, s, fRules)
+			if err != nil {
+				parseErrs[f.Name] = err.Error()
+				return
+			}
+			val = converted
+		}
+		m[f.Name] = val
+	})
+
+	return validateParsed(m, rules, parseErrs, &config{})
+}
@@ -0,0 +1,103 @@
+package valdn
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listFlag is a flag.Value that doesn't implement flag.Getter.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func Test_ValidateFlags(t *testing.T) {
+	newFlagSet := func(args ...string) *flag.FlagSet {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Int("port", 8080, "")
+		fs.String("level", "info", "")
+		fs.String("config", "", "")
+		fs.String("workers", "", "")
+		fs.Duration("timeout", time.Second, "")
+		fs.Bool("debug", false, "")
+		fs.Var(&listFlag{}, "tag", "")
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return fs
+	}
+	tests := []struct {
+		name  string
+		fs    *flag.FlagSet
+		rules Rules
+		want  Errors
+	}{
+		{
+			name: "test ValidateFlags",
+			fs:   newFlagSet("-port", "80800", "-level", "trace", "-config", "app.yaml", "-timeout", "1m", "-tag", "a"),
+			rules: Rules{
+				"port":    {"required", "between:1,65535"},
+				"level":   {"in:debug,info"},
+				"config":  {"required", "regex:\\.yaml$"},
+				"timeout": {"kind:int64", "max:30000000000"},
+				"debug":   {"kind:bool"},
+				"tag":     {"required", "minLen:1"},
+			},
+			want: Errors{
+				"port":    GetErrMsg("between", "1,65535", "port", 80800),
+				"level":   GetErrMsg("in", "debug,info", "level", "trace"),
+				"timeout": GetErrMsg("max", "30000000000", "timeout", time.Minute),
+			},
+		},
+		{
+			name: "test ValidateFlags with default values",
+			fs:   newFlagSet(),
+			rules: Rules{
+				"port":    {"required", "int"},
+				"level":   {"in:debug,info"},
+				"config":  {"regex:\\.yaml$"},
+				"workers": {"int"},
+				"timeout": {"max:1"},
+				"tag":     {"required"},
+			},
+			want: Errors{
+				"port":    GetErrMsg("required", "", "port", nil),
+				"timeout": GetErrMsg("max", "1", "timeout", time.Second),
+				"tag":     GetErrMsg("required", "", "tag", nil),
+			},
+		},
+		{
+			name:  "test ValidateFlags with converted values",
+			fs:    newFlagSet("-workers", "four", "-tag", "7"),
+			rules: Rules{"workers": {"int"}, "tag": {"int", "min:10"}},
+			want: Errors{
+				"workers": GetErrMsg("int", "", "workers", "four"),
+				"tag":     GetErrMsg("min", "10", "tag", 7),
+			},
+		},
+		{
+			name:  "test ValidateFlags with wildcard rules",
+			fs:    newFlagSet("-level", "verbose"),
+			rules: Rules{"*": {"notKind:float64"}, "level": {"maxLen:5"}},
+			want:  Errors{"level": GetErrMsg("maxLen", "5", "level", "verbose")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateFlags(tt.fs, tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateFlags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -7,10 +7,8 @@ toolchain go1.24.0
 require (
 	github.com/google/uuid v1.6.0
 	github.com/nyaruka/phonenumbers v1.6.8
+	golang.org/x/text v0.23.0
+	google.golang.org/protobuf v1.36.11
 )
 
-require (
-	golang.org/x/exp v0.0.0-20250305212735-054e65f0b394 // indirect
-	golang.org/x/text v0.23.0 // indirect
-	google.golang.org/protobuf v1.36.11 // indirect
-)
+require golang.org/x/exp v0.0.0-20250305212735-054e65f0b394 // indirect
@@ -0,0 +1,179 @@
+package valdn
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// groupRules are the names of the rules that group other rules, e.g. anyOf(email; phoneNumber).
+var groupRules = []string{"anyOf", "oneOf", "not", "all"}
+
+// splitGroupRule splits rule group into its name and the rules between its parentheses,
+// and reports weather rule is a group.
+func splitGroupRule(rule string) (string, string, bool) {
+	i := strings.IndexByte(rule, '(')
+	if i <= 0 || !strings.HasSuffix(rule, ")") || !isIn(rule[:i], groupRules) {
+		return "", "", false
+	}
+	return rule[:i], rule[i+1 : len(rule)-1], true
+}
+
+// groupEscaper escapes the characters of rule values that have a meaning in rule groups, see escapeGroupRule.
+var groupEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `(`, `\(`, `)`, `\)`)
+
+// groupUnescaper reverts groupEscaper.
+var groupUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\(`, `(`, `\)`, `)`)
+
+// escapeGroupRule escapes backslashes, semicolons and parentheses of rule r with a backslash, so it can be a rule of
+// a group, e.g. regex:^(a|b);$ is regex:^\(a|b\)\;$. Rule groups are not escaped, their rules are escaped already.
+func escapeGroupRule(r string) string {
+	if _, _, ok := splitGroupRule(r); ok {
+		return r
+	}
+	return groupEscaper.Replace(r)
+}
+
+// splitGroup splits the rules of a group by semicolons that are not in nested groups or escaped, and trims their
+// spaces. Escaped characters of rules that are not groups are unescaped, see escapeGroupRule.
+// It panics if the parentheses of group are not balanced.
+func splitGroup(group string) []string {
+	var rules []string
+	depth, start := 0, 0
+	add := func(end int) {
+		r := strings.TrimSpace(group[start:end])
+		if _, _, ok := splitGroupRule(r); !ok {
+			r = groupUnescaper.Replace(r)
+		}
+		if r != "" {
+			rules = append(rules, r)
+		}
+		start = end + 1
+	}
+	for i := 0; i < len(group) && depth >= 0; i++ {
+		switch group[i] {
+		case '\\':
+			if i+1 < len(group) && strings.IndexByte(`\;()`, group[i+1]) >= 0 {
+				i++
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ';':
+			if depth == 0 {
+				add(i)
+			}
+		}
+	}
+	if depth != 0 {
+		panic(fmt.Errorf("unbalanced parentheses in rule group: %v", group))
+	}
+	add(len(group))
+	return rules
+}
+
+// checkGroup panics if the rules of group, or of its nested groups, are not balanced, not registered or have
+// invalid regular expressions. Groups are checked before they're validated, since rules that panic while validating
+// a group fail instead.
+func checkGroup(group string) {
+	for _, r := range splitGroup(group) {
+		if _, args, ok := splitGroupRule(r); ok {
+			checkGroup(args)
+			continue
+		}
+		rName, rVal := splitRuleNameAndRuleValue(r)
+		if !isRegistered(rName) {
+			panic("unknown rule: " + rName)
+		}
+		if rName == "regex" || rName == "notRegex" {
+			if _, err := regexp.Compile(rVal); err != nil {
+				panic(fmt.Errorf("invalid regular expression in rule group: %v", err))
+			}
+		}
+	}
+}
+
+// validateGroup validates val named name by every rule of group, and returns the errors of the rules that failed,
+// and the rules that passed.
+// Rules that panic for val (e.g. uuid for integers) fail by their error messages.
+// It panics if the group is not valid, see checkGroup.
+func validateGroup(name string, val interface{}, group string) ([]string, []string) {
+	checkGroup(group)
+	var failed, passed []string
+	for _, r := range splitGroup(group) {
+		if err := validateGroupRule(name, val, r); err != nil {
+			failed = append(failed, err.Error())
+		} else {
+			passed = append(passed, r)
+		}
+	}
+	return failed, passed
+}
+
+// validateGroupRule validates val named name by rule r, it returns the error message of r if it panics for val.
+// It panics if r is not registered.
+func validateGroupRule(name string, val interface{}, r string) (err error) {
+	rName, rVal := splitRuleNameAndRuleValue(r)
+	if !isRegistered(rName) {
+		panic("unknown rule: " + rName)
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			err = errors.New(GetErrMsg(rName, rVal, name, val))
+		}
+	}()
+	return Validate(name, val, []string{r})
+}
+
+// anyOfRule checks if val passes at least one of the rules of ruleVal separated by semicolons.
+// It returns error of the errors of all the rules if val doesn't pass any of them.
+func anyOfRule(name string, val interface{}, ruleVal string) error {
+	failed, passed := validateGroup(name, val, ruleVal)
+	if len(passed) == 0 {
+		return errors.New(GetErrMsg("anyOf", strings.Join(failed, "; "), name, val))
+	}
+	return nil
+}
+
+// oneOfRule checks if val passes exactly one of the rules of ruleVal separated by semicolons.
+// It returns error of the errors of all the rules if val doesn't pass any of them,
+// or error of the rules val passes if it passes more than one.
+func oneOfRule(name string, val interface{}, ruleVal string) error {
+	failed, passed := validateGroup(name, val, ruleVal)
+	switch {
+	case len(passed) == 0:
+		return errors.New(GetErrMsg("oneOf", strings.Join(failed, "; "), name, val))
+	case len(passed) > 1:
+		return errors.New(GetErrMsg("oneOf", "only one of "+strings.Join(passed, "; "), name, val))
+	}
+	return nil
+}
+
+// notRule checks if val doesn't pass all the rules of ruleVal separated by semicolons, e.g. not(in:a,b) is notIn:a,b.
+// The not rules (notIn, notRegex...) are not deprecated by it, their errors name the values they reject.
+// It returns error if val passes all of them.
+func notRule(name string, val interface{}, ruleVal string) error {
+	if failed, _ := validateGroup(name, val, ruleVal); len(failed) == 0 {
+		return errors.New(GetErrMsg("not", strings.Join(splitGroup(ruleVal), "; "), name, val))
+	}
+	return nil
+}
+
+// allRule checks if val passes all the rules of ruleVal separated by semicolons, it's used to nest rule chains in
+// other groups, e.g. anyOf(uuid; all(int; min:1)).
+// It returns error of the errors of the rules val doesn't pass.
+func allRule(name string, val interface{}, ruleVal string) error {
+	if failed, _ := validateGroup(name, val, ruleVal); len(failed) > 0 {
+		return errors.New(GetErrMsg("all", strings.Join(failed, "; "), name, val))
+	}
+	return nil
+}
+
+func init() {
+	AddRule("anyOf", anyOfRule, "[name] must match any of: [ruleVal]")
+	AddRule("oneOf", oneOfRule, "[name] must match exactly one of: [ruleVal]")
+	AddRule("not", notRule, "[name] must not match: [ruleVal]")
+	AddRule("all", allRule, "[name] must match all of: [ruleVal]")
+}
@@ -0,0 +1,341 @@
+package valdn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_splitGroupRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      string
+		wantName  string
+		wantVal   string
+		wantGroup bool
+	}{
+		{
+			name:      "test split group rule",
+			rule:      "anyOf(email; phoneNumber)",
+			wantName:  "anyOf",
+			wantVal:   "email; phoneNumber",
+			wantGroup: true,
+		},
+		{
+			name:      "test split nested group rule",
+			rule:      "oneOf(uuid; all(int; min:1))",
+			wantName:  "oneOf",
+			wantVal:   "uuid; all(int; min:1)",
+			wantGroup: true,
+		},
+		{
+			name:      "test split rule that is not a group",
+			rule:      "regex:^(a|b)$",
+			wantGroup: false,
+		},
+		{
+			name:      "test split rule of unknown group",
+			rule:      "some(email)",
+			wantGroup: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotVal, gotGroup := splitGroupRule(tt.rule)
+			if gotName != tt.wantName || gotVal != tt.wantVal || gotGroup != tt.wantGroup {
+				t.Errorf("splitGroupRule() = %v, %v, %v, want %v, %v, %v", gotName, gotVal, gotGroup, tt.wantName,
+					tt.wantVal, tt.wantGroup)
+			}
+		})
+	}
+}
+
+func Test_splitGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		group     string
+		want      []string
+		wantPanic bool
+	}{
+		{
+			name:  "test split group",
+			group: "email;  phoneNumber ",
+			want:  []string{"email", "phoneNumber"},
+		},
+		{
+			name:  "test split group with nested groups",
+			group: "uuid; all(int; min:1); not(in:a,b)",
+			want:  []string{"uuid", "all(int; min:1)", "not(in:a,b)"},
+		},
+		{
+			name:      "test split group with unbalanced parentheses",
+			group:     "uuid; all(int; min:1",
+			wantPanic: true,
+		},
+		{
+			name:      "test split group with extra closing parentheses",
+			group:     "uuid); all(int",
+			wantPanic: true,
+		},
+		{
+			name:  "test split group with escaped characters",
+			group: `regex:^\(a|b\)\;$; regex:^\d\\$`,
+			want:  []string{`regex:^(a|b);$`, `regex:^\d\$`},
+		},
+		{
+			name:  "test split group with escaped characters of nested groups",
+			group: `int; not(regex:\); len:2)`,
+			want:  []string{"int", `not(regex:\); len:2)`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("splitGroup() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := splitGroup(tt.group); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitGroup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_escapeGroupRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want string
+	}{
+		{name: "test escapeGroupRule", rule: `regex:^\d(a|b);$`, want: `regex:^\\d\(a|b\)\;$`},
+		{name: "test escapeGroupRule with group", rule: "all(int; min:1)", want: "all(int; min:1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := escapeGroupRule(tt.rule)
+			if got != tt.want {
+				t.Errorf("escapeGroupRule() = %v, want %v", got, tt.want)
+			}
+			if rules := splitGroup(got); !reflect.DeepEqual(rules, []string{tt.rule}) {
+				t.Errorf("splitGroup(escapeGroupRule()) = %v, want %v", rules, tt.rule)
+			}
+		})
+	}
+}
+
+func Test_anyOfRule(t *testing.T) {
+	type args struct {
+		name    string
+		val     interface{}
+		ruleVal string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantErr   string
+		wantPanic bool
+	}{
+		{
+			name:    "test anyOf rule with first alternative",
+			args:    args{name: "contact", val: "test@test.test", ruleVal: "email; phoneNumber"},
+			wantErr: "",
+		},
+		{
+			name:    "test anyOf rule with second alternative",
+			args:    args{name: "contact", val: "+201234567890", ruleVal: "email; phoneNumber"},
+			wantErr: "",
+		},
+		{
+			name: "test anyOf rule with no alternative",
+			args: args{name: "contact", val: "test", ruleVal: "email; phoneNumber"},
+			wantErr: "contact must match any of: contact must be a valid email address; " +
+				GetErrMsg("phoneNumber", "", "contact", "test"),
+		},
+		{
+			name:    "test anyOf rule with alternative that panics",
+			args:    args{name: "id", val: 5, ruleVal: "uuid; all(int; min:1)"},
+			wantErr: "",
+		},
+		{
+			name: "test anyOf rule with nested group that fails",
+			args: args{name: "id", val: -5, ruleVal: "uuid; all(int; min:1)"},
+			wantErr: "id must match any of: " + GetErrMsg("uuid", "", "id", -5) + "; id must match all of: " +
+				GetErrMsg("min", "1", "id", -5),
+		},
+		{
+			name:      "test anyOf rule with unknown rule",
+			args:      args{name: "id", val: 5, ruleVal: "uuid; unknown"},
+			wantPanic: true,
+		},
+		{
+			name:    "test anyOf rule with escaped regex",
+			args:    args{name: "code", val: "b;", ruleVal: `int; regex:^\(a|b\)\;$`},
+			wantErr: "",
+		},
+		{
+			name:      "test anyOf rule with unknown rule of nested group",
+			args:      args{name: "id", val: 5, ruleVal: "int; all(uuid; unknown)"},
+			wantPanic: true,
+		},
+		{
+			name:      "test anyOf rule with unbalanced nested group",
+			args:      args{name: "id", val: 5, ruleVal: "int; all(regex:a); uuid)"},
+			wantPanic: true,
+		},
+		{
+			name:      "test anyOf rule with invalid regex",
+			args:      args{name: "id", val: "a", ruleVal: `int; all(regex:a\))`},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("anyOfRule() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			err := anyOfRule(tt.args.name, tt.args.val, tt.args.ruleVal)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("anyOfRule() err = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_oneOfRule(t *testing.T) {
+	type args struct {
+		name    string
+		val     interface{}
+		ruleVal string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr string
+	}{
+		{
+			name:    "test oneOf rule with one alternative",
+			args:    args{name: "code", val: "ab", ruleVal: "len:2; len:3"},
+			wantErr: "",
+		},
+		{
+			name: "test oneOf rule with no alternative",
+			args: args{name: "code", val: "a", ruleVal: "len:2; len:3"},
+			wantErr: "code must match exactly one of: " + GetErrMsg("len", "2", "code", "a") + "; " +
+				GetErrMsg("len", "3", "code", "a"),
+		},
+		{
+			name:    "test oneOf rule with more than one alternative",
+			args:    args{name: "code", val: "ab", ruleVal: "len:2; in:ab,cd"},
+			wantErr: "code must match exactly one of: only one of len:2; in:ab,cd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := oneOfRule(tt.args.name, tt.args.val, tt.args.ruleVal)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("oneOfRule() err = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_notRule(t *testing.T) {
+	type args struct {
+		name    string
+		val     interface{}
+		ruleVal string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr string
+	}{
+		{
+			name:    "test not rule",
+			args:    args{name: "country", val: "EGYPT", ruleVal: "in:GREECE,CYPRUS"},
+			wantErr: "",
+		},
+		{
+			name:    "test not rule with value that passes",
+			args:    args{name: "country", val: "GREECE", ruleVal: "in:GREECE,CYPRUS"},
+			wantErr: "country must not match: in:GREECE,CYPRUS",
+		},
+		{
+			name:    "test not rule of rules that one of them fails",
+			args:    args{name: "age", val: 15, ruleVal: "int; min:18"},
+			wantErr: "",
+		},
+		{
+			name:    "test not rule of rules that all of them pass",
+			args:    args{name: "age", val: 20, ruleVal: "int;min:18"},
+			wantErr: "age must not match: int; min:18",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notRule(tt.args.name, tt.args.val, tt.args.ruleVal)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("notRule() err = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_allRule(t *testing.T) {
+	type args struct {
+		name    string
+		val     interface{}
+		ruleVal string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr string
+	}{
+		{
+			name:    "test all rule",
+			args:    args{name: "age", val: 20, ruleVal: "int; min:18; max:99"},
+			wantErr: "",
+		},
+		{
+			name: "test all rule with failed rules",
+			args: args{name: "age", val: 200, ruleVal: "int; min:18; max:99; equal:5"},
+			wantErr: "age must match all of: " + GetErrMsg("max", "99", "age", 200) + "; " +
+				GetErrMsg("equal", "5", "age", 200),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := allRule(tt.args.name, tt.args.val, tt.args.ruleVal)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("allRule() err = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ValidateCollection_groups(t *testing.T) {
+	val := map[string]interface{}{"contact": "test", "role": "root", "id": 5}
+	rules := Rules{
+		"contact": {"required", "anyOf(email; phoneNumber)"},
+		"role":    {"required", "not(in:root,admin)"},
+		"id":      {"required", "oneOf(uuid; all(int; min:1))"},
+	}
+	want := Errors{
+		"contact": "contact must match any of: contact must be a valid email address; " +
+			GetErrMsg("phoneNumber", "", "contact", "test"),
+		"role": "role must not match: in:root,admin",
+	}
+	if got := ValidateCollection(val, rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateCollection() = %v, want %v", got, want)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -3,6 +3,9 @@ package valdn
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"mime"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -20,22 +23,43 @@ func copyRules(r Rules) Rules {
 }
 
 func toString(val interface{}) string {
+	if r, ok := val.(*big.Rat); ok {
+		return ratString(r)
+	}
 	return fmt.Sprint(val)
 }
 
+// ratString returns r in decimal notation, with as many decimal places as it needs to be exact (up to 1000).
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	x := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	prec := 0
+	for !x.IsInt() && prec < 1000 {
+		x.Mul(x, ten)
+		prec++
+	}
+	return r.FloatString(prec)
+}
+
 func splitRuleNameAndRuleValue(rule string) (string, string) {
-	if strings.ContainsRune(rule, ':') {
-		ruleSpliced := strings.Split(rule, ":")
-		return ruleSpliced[0], ruleSpliced[1]
+	if name, val, ok := splitGroupRule(rule); ok {
+		return name, val
 	}
-	return rule, ""
+	// values may have colons, e.g. timeFormat:2006-01-02T15:04:05Z07:00
+	name, val, _ := strings.Cut(rule, ":")
+	return name, val
 }
 
-func makeParentNameJoinable(name string) string {
-	if name != "" && name[len(name)-1] != '.' {
-		return name + "."
+func isIn(s string, items []string) bool {
+	for _, i := range items {
+		if i == s {
+			return true
+		}
 	}
-	return name
+	return false
 }
 
 func getParentName(name string) string {
@@ -46,13 +70,13 @@ func getParentName(name string) string {
 	return ""
 }
 
-func getStructFieldInfo(number int, parTyp reflect.Type, parVal reflect.Value, parName string) (string, reflect.Type, reflect.Value) {
+func getStructFieldInfo(number int, parTyp reflect.Type, parVal reflect.Value, parPath Path) (Path, reflect.Type, reflect.Value) {
 	field := parTyp.Field(number)
-	name := parName + field.Name
+	p := parPath.child(field.Name)
 	typ := field.Type
 	val := parVal.Field(number)
 
-	return name, typ, val
+	return p, typ, val
 }
 
 func convertInterfaceToMap(value interface{}) map[string]interface{} {
@@ -73,66 +97,76 @@ func convertInterfaceToSlice(value interface{}) []interface{} {
 	return s
 }
 
-// stringToFloat converts val to float64.
-// It returns error if val is not a float or an integer.
-func interfaceToFloat(val interface{}) (float64, error) {
-	var f64 float64
-	if !IsInteger(val) && !IsFloat(val) {
-		return f64, errors.New("val must be an integer or a float")
-	}
-	if v, ok := val.(float64); ok {
-		f64 = v
-	}
-	if v, ok := val.(float32); ok {
-		f64 = float64(v)
-	}
-	if v, ok := val.(int); ok {
-		f64 = float64(v)
-	}
-	if v, ok := val.(uint); ok {
-		f64 = float64(v)
-	}
-	if v, ok := val.(int8); ok {
-		f64 = float64(v)
-	}
-	if v, ok := val.(uint8); ok {
-		f64 = float64(v)
-	}
-	if v, ok := val.(int16); ok {
-		f64 = float64(v)
-	}
-	if v, ok := val.(uint16); ok {
-		f64 = float64(v)
+// numberString returns number val in decimal notation without exponent, e.g. 1000000000000000000000 for 1e21.
+func numberString(val interface{}) string {
+	switch v := val.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return toString(val)
+}
+
+// interfaceToRat converts val to big.Rat exactly, floats are converted by their shortest decimal representation,
+// so 0.1 is converted to 1/10.
+// It returns error if val is not an integer or a finite float.
+func interfaceToRat(val interface{}) (*big.Rat, error) {
+	switch v := val.(type) {
+	case *big.Int:
+		return new(big.Rat).SetInt(v), nil
+	case *big.Rat:
+		return new(big.Rat).Set(v), nil
 	}
-	if v, ok := val.(int32); ok {
-		f64 = float64(v)
+	if !IsInteger(val) && !IsFloat(val) {
+		return nil, errors.New("val must be an integer or a float")
 	}
-	if v, ok := val.(uint32); ok {
-		f64 = float64(v)
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetUint64(rv.Uint()), nil
 	}
-	if v, ok := val.(int64); ok {
-		f64 = float64(v)
+	bitSize := 64
+	if rv.Kind() == reflect.Float32 {
+		bitSize = 32
 	}
-	if v, ok := val.(uint64); ok {
-		f64 = float64(v)
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rv.Float(), 'g', -1, bitSize))
+	if !ok {
+		return nil, errors.New("val must be a finite float")
 	}
-	return f64, nil
+	return r, nil
 }
 
-// stringToFloat converts s to float64.
+// stringToRat converts s to big.Rat exactly.
 // It returns error if s is not a float or an integer.
-func stringToFloat(s string) (float64, error) {
-	var f64 float64
-	i, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		f64, err = strconv.ParseFloat(s, 64)
-		if err != nil {
-			return f64, errors.New("string must contain an integer or a float")
+func stringToRat(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.Contains(s, "/") {
+		return nil, errors.New("string must contain an integer or a float")
+	}
+	return r, nil
+}
+
+// compareNumber compares val with r without float rounding, it returns -1 if val < r, 0 if val == r and +1 if val > r.
+// Infinite floats are greater or lower than every r, and NaN compares equal to every r.
+// It returns error if val is not an integer or a float.
+func compareNumber(val interface{}, r *big.Rat) (int, error) {
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64 {
+		f := rv.Float()
+		switch {
+		case math.IsNaN(f):
+			return 0, nil
+		case math.IsInf(f, 0):
+			return int(math.Copysign(1, f)), nil
 		}
-	} else {
-		f64 = float64(i)
 	}
-	return f64, nil
+	v, err := interfaceToRat(val)
+	if err != nil {
+		return 0, err
+	}
+	return v.Cmp(r), nil
 }
 
 // getLen gets v's length.
@@ -142,15 +176,13 @@ func getLen(v interface{}) (int, error) {
 	case IsMap(v) || IsSlice(v) || IsArray(v) || IsString(v):
 		return reflect.ValueOf(v).Len(), nil
 	case IsInteger(v) || IsFloat(v):
+		// the length of a number is the count of its digits
 		l := 0
-		stringVal := toString(v)
-		if stringVal[0] == '-' {
-			l -= 1
+		for _, c := range numberString(v) {
+			if c >= '0' && c <= '9' {
+				l++
+			}
 		}
-		if IsFloat(v) {
-			l -= 1
-		}
-		l += len(stringVal)
 		return l, nil
 	default:
 		return 0, fmt.Errorf("can't get length of kind %v", reflect.TypeOf(v).Kind())
@@ -177,6 +209,37 @@ func getFileSize(v interface{}) (int64, error) {
 	return 0, fmt.Errorf("%v is not type of *os.File or *multipart.FileHeader", v)
 }
 
+// getFileMIME returns the media type of v, declared by its Content-Type header if v is a multipart.FileHeader,
+// or guessed by its extension if v is an os.File. Files of unknown media types are application/octet-stream.
+func getFileMIME(v interface{}) (string, error) {
+	var mediaType string
+	switch f := v.(type) {
+	case *os.File:
+		if (os.File{}) == *f {
+			return "", errors.New("can't get media type from empty os.File")
+		}
+		mediaType = mime.TypeByExtension(filepath.Ext(f.Name()))
+	case *multipart.FileHeader:
+		mediaType = f.Header.Get("Content-Type")
+	default:
+		return "", fmt.Errorf("%v is not type of *os.File or *multipart.FileHeader", v)
+	}
+	mediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "application/octet-stream", nil
+	}
+	return mediaType, nil
+}
+
+// isMIMEMatch reports weather mediaType matches pattern, patterns like image/* match every media type of that type.
+func isMIMEMatch(mediaType string, pattern string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, pattern[:len(pattern)-1])
+	}
+	return mediaType == pattern
+}
+
 func getFileExt(v interface{}) (string, error) {
 	if f, ok := v.(*os.File); ok {
 		if (os.File{}) == *f {
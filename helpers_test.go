@@ -2,10 +2,13 @@ package valdn
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"mime/multipart"
 	"net/textproto"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -138,6 +141,18 @@ func Test_splitRuleNameAndRuleValue(t *testing.T) {
 			nameExpected:  "val",
 			valueExpected: "",
 		},
+		{
+			name:          "test get rule value from rule group",
+			rule:          "anyOf(email; timeFormat:15:04)",
+			nameExpected:  "anyOf",
+			valueExpected: "email; timeFormat:15:04",
+		},
+		{
+			name:          "test get rule value that has colons",
+			rule:          "timeFormat:2006-01-02T15:04:05Z07:00",
+			nameExpected:  "timeFormat",
+			valueExpected: "2006-01-02T15:04:05Z07:00",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -148,32 +163,30 @@ func Test_splitRuleNameAndRuleValue(t *testing.T) {
 	}
 }
 
-func Test_makeParentNameJoinable(t *testing.T) {
+func Test_isIn(t *testing.T) {
 	tests := []struct {
-		name    string
-		parName string
-		want    string
+		name  string
+		s     string
+		items []string
+		want  bool
 	}{
 		{
-			name:    "test make parent rName joinable",
-			parName: "Parent",
-			want:    "Parent.",
-		},
-		{
-			name:    "test make parent rName joinable with . at the end",
-			parName: "Parent.",
-			want:    "Parent.",
+			name:  "test isIn",
+			s:     "body",
+			items: []string{"query", "body"},
+			want:  true,
 		},
 		{
-			name:    "test make empty parent rName joinable",
-			parName: "",
-			want:    "",
+			name:  "test isIn with non exist item",
+			s:     "header",
+			items: []string{"query", "body"},
+			want:  false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := makeParentNameJoinable(tt.parName); got != tt.want {
-				t.Errorf("makeParentNameJoinable() = %v, expectedRulesCount %v", got, tt.want)
+			if got := isIn(tt.s, tt.items); got != tt.want {
+				t.Errorf("isIn() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -225,7 +238,7 @@ func Test_getStructFieldInfo(t *testing.T) {
 		fNumber int
 		pType   reflect.Type
 		pValue  reflect.Value
-		parName string
+		parPath Path
 	}
 	tests := []struct {
 		name               string
@@ -241,7 +254,7 @@ func Test_getStructFieldInfo(t *testing.T) {
 				fNumber: 1,
 				pType:   reflect.TypeOf(parentStruct),
 				pValue:  reflect.ValueOf(parentStruct),
-				parName: "",
+				parPath: nil,
 			},
 			fName:              "Age",
 			fType:              reflect.TypeOf(parentStruct.Age),
@@ -251,8 +264,8 @@ func Test_getStructFieldInfo(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fieldName, fieldType, fieldValue := getStructFieldInfo(tt.args.fNumber, tt.args.pType, tt.args.pValue, tt.args.parName)
-			if fieldName != tt.fName {
+			fieldPath, fieldType, fieldValue := getStructFieldInfo(tt.args.fNumber, tt.args.pType, tt.args.pValue, tt.args.parPath)
+			if fieldName := fieldPath.String(); fieldName != tt.fName {
 				t.Errorf("getStructFieldInfo() got = %v, expectedRulesCount %v", fieldName, tt.fName)
 			}
 			if !reflect.DeepEqual(fieldType, tt.fType) {
@@ -314,182 +327,6 @@ func Test_convertInterfaceToSlice(t *testing.T) {
 	}
 }
 
-func Test_interfaceToFloat(t *testing.T) {
-	type args struct {
-		val interface{}
-	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		want    float64
-	}{
-		{
-			name: "test interfaceToFloat with string",
-			args: args{
-				val: "s",
-			},
-			wantErr: true,
-			want:    0.0,
-		},
-		{
-			name: "test interfaceToFloat with float64",
-			args: args{
-				val: 3.14,
-			},
-			wantErr: false,
-			want:    3.14,
-		},
-		{
-			name: "test interfaceToFloat with float32",
-			args: args{
-				val: float32(3.14),
-			},
-			wantErr: false,
-			want:    3.140000104904175,
-		},
-		{
-			name: "test interfaceToFloat with int",
-			args: args{
-				val: 3,
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with uint",
-			args: args{
-				val: uint(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with int8",
-			args: args{
-				val: int8(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with uint8",
-			args: args{
-				val: uint8(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with int16",
-			args: args{
-				val: int16(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with uint16",
-			args: args{
-				val: uint16(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with int32",
-			args: args{
-				val: int32(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with uint32",
-			args: args{
-				val: uint32(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with int64",
-			args: args{
-				val: int64(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-		{
-			name: "test interfaceToFloat with uint64()",
-			args: args{
-				val: uint64(3),
-			},
-			wantErr: false,
-			want:    3,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := interfaceToFloat(tt.args.val)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("interfaceToFloat() err = %v, wantErr %v", err, tt.wantErr)
-			}
-			if got != tt.want {
-				t.Errorf("interfaceToFloat() got = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_stringToFloat(t *testing.T) {
-	type args struct {
-		s string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		want    float64
-	}{
-		{
-			name: "test stringToFloat with string contains float",
-			args: args{
-				s: "3.14",
-			},
-			wantErr: false,
-			want:    3.14,
-		},
-		{
-			name: "test stringToFloat with string contains integer",
-			args: args{
-				s: "3",
-			},
-			wantErr: false,
-			want:    3.0,
-		},
-		{
-			name: "test stringToFloat with string",
-			args: args{
-				s: "string",
-			},
-			wantErr: true,
-			want:    0.0,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := stringToFloat(tt.args.s)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("stringToFloat() err = %v, wantErr %v", err, tt.wantErr)
-			}
-			if got != tt.want {
-				t.Errorf("stringToFloat() got1 = %v, wantErr %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func Test_getLen(t *testing.T) {
 	type args struct {
 		v interface{}
@@ -572,6 +409,24 @@ func Test_getLen(t *testing.T) {
 			wantErr: true,
 			want:    0,
 		},
+		{
+			name:    "test getLen with float in exponent notation",
+			args:    args{v: 1e21},
+			wantErr: false,
+			want:    22,
+		},
+		{
+			name:    "test getLen with big.Int",
+			args:    args{v: big.NewInt(-12345)},
+			wantErr: false,
+			want:    5,
+		},
+		{
+			name:    "test getLen with big.Rat",
+			args:    args{v: big.NewRat(-12345, 1000)},
+			wantErr: false,
+			want:    5,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -694,3 +549,201 @@ func Test_getFileExt(t *testing.T) {
 		})
 	}
 }
+
+func Test_getFileMIME(t *testing.T) {
+	f, err := os.Open("example.json")
+	if err != nil {
+		panic(err)
+	}
+	tests := []struct {
+		name     string
+		v        interface{}
+		wantErr  bool
+		wantMIME string
+	}{
+		{
+			name:     "test getFileMIME with multipart.FileHeader",
+			v:        &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{"Content-Type": {"Image/PNG; q=1"}}},
+			wantErr:  false,
+			wantMIME: "image/png",
+		},
+		{
+			name:     "test getFileMIME with multipart.FileHeader without content type",
+			v:        &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{}},
+			wantErr:  false,
+			wantMIME: "application/octet-stream",
+		},
+		{
+			name:     "test getFileMIME with os.File",
+			v:        f,
+			wantErr:  false,
+			wantMIME: "application/json",
+		},
+		{
+			name:     "test getFileMIME with empty os.File",
+			v:        &os.File{},
+			wantErr:  true,
+			wantMIME: "",
+		},
+		{
+			name:     "test getFileMIME with non-file",
+			v:        "bla",
+			wantErr:  true,
+			wantMIME: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getFileMIME(tt.v)
+			if got != tt.wantMIME {
+				t.Errorf("getFileMIME() got = %v, want %v", got, tt.wantMIME)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getFileMIME() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_isMIMEMatch(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		pattern   string
+		want      bool
+	}{
+		{mediaType: "image/png", pattern: "image/png", want: true},
+		{mediaType: "image/png", pattern: "Image/*", want: true},
+		{mediaType: "image/png", pattern: "image/jpeg", want: false},
+		{mediaType: "imagex/png", pattern: "image/*", want: false},
+	}
+	for _, tt := range tests {
+		t.Run("test isMIMEMatch with "+tt.mediaType+" and "+tt.pattern, func(t *testing.T) {
+			if got := isMIMEMatch(tt.mediaType, tt.pattern); got != tt.want {
+				t.Errorf("isMIMEMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_toString_bigRat(t *testing.T) {
+	tests := []struct {
+		name string
+		val  *big.Rat
+		want string
+	}{
+		{name: "test toString with integer big.Rat", val: big.NewRat(10, 2), want: "5"},
+		{name: "test toString with decimal big.Rat", val: big.NewRat(-1, 8), want: "-0.125"},
+		{name: "test toString with repeating big.Rat", val: big.NewRat(1, 3), want: "0." + strings.Repeat("3", 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toString(tt.val); got != tt.want {
+				t.Errorf("toString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_numberString(t *testing.T) {
+	tests := []struct {
+		name string
+		val  interface{}
+		want string
+	}{
+		{name: "test numberString with int", val: 15, want: "15"},
+		{name: "test numberString with float64", val: 1e21, want: "1000000000000000000000"},
+		{name: "test numberString with float32", val: float32(0.1), want: "0.1"},
+		{name: "test numberString with big.Rat", val: big.NewRat(3, 2), want: "1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := numberString(tt.val); got != tt.want {
+				t.Errorf("numberString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_interfaceToRat(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     interface{}
+		want    *big.Rat
+		wantErr bool
+	}{
+		{name: "test interfaceToRat with int", val: -15, want: big.NewRat(-15, 1)},
+		{name: "test interfaceToRat with uint64", val: uint64(math.MaxUint64), want: new(big.Rat).SetUint64(math.MaxUint64)},
+		{name: "test interfaceToRat with float64", val: 0.1, want: big.NewRat(1, 10)},
+		{name: "test interfaceToRat with float32", val: float32(0.1), want: big.NewRat(1, 10)},
+		{name: "test interfaceToRat with big.Int", val: big.NewInt(7), want: big.NewRat(7, 1)},
+		{name: "test interfaceToRat with big.Rat", val: big.NewRat(1, 3), want: big.NewRat(1, 3)},
+		{name: "test interfaceToRat with infinite float", val: math.Inf(1), wantErr: true},
+		{name: "test interfaceToRat with string", val: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interfaceToRat(tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("interfaceToRat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Cmp(tt.want) != 0 {
+				t.Errorf("interfaceToRat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_stringToRat(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    *big.Rat
+		wantErr bool
+	}{
+		{name: "test stringToRat with integer", s: "9007199254740993", want: new(big.Rat).SetInt64(9007199254740993)},
+		{name: "test stringToRat with float", s: "-0.1", want: big.NewRat(-1, 10)},
+		{name: "test stringToRat with exponent", s: "1e3", want: big.NewRat(1000, 1)},
+		{name: "test stringToRat with fraction", s: "1/3", wantErr: true},
+		{name: "test stringToRat with text", s: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stringToRat(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("stringToRat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Cmp(tt.want) != 0 {
+				t.Errorf("stringToRat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compareNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     interface{}
+		r       *big.Rat
+		want    int
+		wantErr bool
+	}{
+		{name: "test compareNumber with lower int", val: 1, r: big.NewRat(2, 1), want: -1},
+		{name: "test compareNumber with equal float", val: 0.1, r: big.NewRat(1, 10), want: 0},
+		{name: "test compareNumber with greater int64 above 2^53", val: int64(9007199254740993), r: new(big.Rat).SetInt64(9007199254740992), want: 1},
+		{name: "test compareNumber with positive infinity", val: math.Inf(1), r: big.NewRat(1, 1), want: 1},
+		{name: "test compareNumber with negative infinity", val: math.Inf(-1), r: big.NewRat(1, 1), want: -1},
+		{name: "test compareNumber with NaN", val: math.NaN(), r: big.NewRat(1, 1), want: 0},
+		{name: "test compareNumber with string", val: "1", r: big.NewRat(1, 1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compareNumber(tt.val, tt.r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compareNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("compareNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package valdn
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrMalformedJSON is wrapped by JSONError when a document is not valid JSON.
+	ErrMalformedJSON = errors.New("malformed JSON")
+	// ErrDuplicateKey is wrapped by JSONError when an object has the same key more than once.
+	ErrDuplicateKey = errors.New("duplicate key")
+	// ErrTrailingData is wrapped by JSONError when a document has data after its top-level value,
+	// and by the error of an XML document that has elements after its root element.
+	ErrTrailingData = errors.New("trailing data after top-level value")
+	// ErrInvalidUTF8 is wrapped by JSONError when a string is not valid UTF-8.
+	ErrInvalidUTF8 = errors.New("invalid UTF-8")
+	// ErrLoneSurrogate is wrapped by JSONError when a string escapes half of a UTF-16 surrogate pair.
+	ErrLoneSurrogate = errors.New("lone surrogate")
+	// ErrUnknownField is wrapped by JSONError when an object has a key that has no rules.
+	ErrUnknownField = errors.New("unknown field")
+)
+
+// JSONError is the error of a document rejected by strict JSON decoding, see WithStrictJSON.
+// Offset is the byte offset of the rejected data, and Pointer is the JSON Pointer (RFC 6901) of the value that has it.
+type JSONError struct {
+	Offset  int
+	Pointer string
+	Err     error
+}
+
+func (e *JSONError) Error() string {
+	return fmt.Sprintf("%v at offset %v (%q)", e.Err, e.Offset, e.Pointer)
+}
+
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// decodeJSON decodes data token by token and converts its numbers by parseJSONVal, after it's checked by the strict
+// JSON decoding of c. The limits of c (MaxDepth, MaxKeys and MaxArrayLen) are checked while data is decoded,
+// so values that exceed them are not built.
+func decodeJSON(data []byte, rules Rules, c *config) (interface{}, error) {
+	if err := c.checkJSON(data, rules); err != nil {
+		return nil, err
+	}
+	d := &jsonDecoder{dec: json.NewDecoder(bytes.NewReader(data)), l: c.limits}
+	d.dec.UseNumber()
+	root, err := d.value("", 0)
+	if err == io.ErrUnexpectedEOF {
+		// same error as json.Unmarshal
+		return nil, errJSONEnd
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = d.dec.Token(); err == nil {
+		return nil, &JSONError{Offset: int(d.dec.InputOffset()), Err: ErrTrailingData}
+	} else if err != io.EOF {
+		return nil, err
+	}
+	return parseJSONVal(root), nil
+}
+
+// errJSONEnd is returned by decodeJSON if data ends before its value.
+var errJSONEnd = errors.New("unexpected end of JSON input")
+
+// jsonDecoder decodes JSON values of dec, numbers are decoded as json.Number.
+type jsonDecoder struct {
+	dec *json.Decoder
+	l   Limits
+}
+
+// value decodes the value named name of nesting depth, and returns an error if it exceeds the limits of d.
+func (d *jsonDecoder) value(name string, depth int) (interface{}, error) {
+	tok, err := d.dec.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	var val interface{}
+	if delim == '[' {
+		s := make([]interface{}, 0)
+		for d.dec.More() {
+			if d.l.MaxArrayLen > 0 && len(s) >= d.l.MaxArrayLen {
+				return nil, fmt.Errorf("%w: %v has more than %v items", ErrArrayTooLong, rootName(name), d.l.MaxArrayLen)
+			}
+			if err = d.l.checkJSONDepth(name, depth); err != nil {
+				return nil, err
+			}
+			item, err := d.value(joinName(name, strconv.Itoa(len(s))), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, item)
+		}
+		val = s
+	} else {
+		m := make(map[string]interface{})
+		for d.dec.More() {
+			if err = d.l.checkJSONDepth(name, depth); err != nil {
+				return nil, err
+			}
+			tok, err = d.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := tok.(string)
+			if _, ok = m[key]; !ok && d.l.MaxKeys > 0 && len(m) >= d.l.MaxKeys {
+				return nil, fmt.Errorf("%w: %v has more than %v keys", ErrTooManyKeys, rootName(name), d.l.MaxKeys)
+			}
+			if m[key], err = d.value(joinName(name, key), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		val = m
+	}
+	// closing delimiter
+	if _, err = d.dec.Token(); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// checkJSONDepth returns ErrTooDeep if the items of the JSON value named name, of nesting depth, exceed MaxDepth,
+// or maxJSONDepth if MaxDepth is not set.
+func (l Limits) checkJSONDepth(name string, depth int) error {
+	max := l.MaxDepth
+	if max <= 0 || max > maxJSONDepth {
+		max = maxJSONDepth
+	}
+	if depth+1 > max {
+		return fmt.Errorf("%w: %v is nested more than %v levels", ErrTooDeep, rootName(name), max)
+	}
+	return nil
+}
+
+// maxJSONDepth is the max nesting depth of JSON documents if Limits.MaxDepth is not set, like encoding/json.
+const maxJSONDepth = 10000
+
+// strictScanner walks a JSON document and rejects what encoding/json silently accepts.
+type strictScanner struct {
+	data     []byte
+	pos      int
+	path     Path
+	known    func(p Path) bool
+	maxDepth int
+	depth    int
+}
+
+// checkStrictJSON returns JSONError if data is not valid JSON, has duplicate keys, trailing data, invalid UTF-8
+// or lone surrogates, or if it's nested deeper than maxDepth (maxJSONDepth if it's not positive) by ErrTooDeep.
+// If known is not nil, object keys it doesn't know are rejected as unknown fields.
+func checkStrictJSON(data []byte, known func(p Path) bool, maxDepth int) error {
+	if maxDepth <= 0 || maxDepth > maxJSONDepth {
+		maxDepth = maxJSONDepth
+	}
+	s := &strictScanner{data: data, known: known, maxDepth: maxDepth}
+	if err := s.value(); err != nil {
+		return err
+	}
+	s.skipSpaces()
+	if s.pos < len(s.data) {
+		return s.error(ErrTrailingData)
+	}
+	return nil
+}
+
+func (s *strictScanner) error(err error) *JSONError {
+	return &JSONError{Offset: s.pos, Pointer: JSONPointerPath(s.path), Err: err}
+}
+
+func (s *strictScanner) skipSpaces() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// enter returns JSONError of ErrTooDeep if the items of the object or the array at pos are nested deeper than
+// maxDepth, leave must be called after they're scanned.
+func (s *strictScanner) enter() error {
+	s.depth++
+	if s.depth > s.maxDepth {
+		return s.error(ErrTooDeep)
+	}
+	return nil
+}
+
+func (s *strictScanner) leave() {
+	s.depth--
+}
+
+func (s *strictScanner) value() error {
+	s.skipSpaces()
+	if s.pos >= len(s.data) {
+		return s.error(ErrMalformedJSON)
+	}
+	switch c := s.data[s.pos]; {
+	case c == '{':
+		return s.object()
+	case c == '[':
+		return s.array()
+	case c == '"':
+		_, err := s.string()
+		return err
+	case c == 't':
+		return s.literal("true")
+	case c == 'f':
+		return s.literal("false")
+	case c == 'n':
+		return s.literal("null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return s.number()
+	}
+	return s.error(ErrMalformedJSON)
+}
+
+func (s *strictScanner) object() error {
+	s.pos++ // {
+	s.skipSpaces()
+	if s.pos < len(s.data) && s.data[s.pos] == '}' {
+		s.pos++
+		return nil
+	}
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	keys := make(map[string]bool)
+	for {
+		s.skipSpaces()
+		if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+			return s.error(ErrMalformedJSON)
+		}
+		keyPos := s.pos
+		key, err := s.string()
+		if err != nil {
+			return err
+		}
+		s.path = s.path.child(key)
+		if keys[key] {
+			s.pos = keyPos
+			return s.error(ErrDuplicateKey)
+		}
+		keys[key] = true
+		if s.known != nil && !s.known(s.path) {
+			s.pos = keyPos
+			return s.error(ErrUnknownField)
+		}
+
+		s.skipSpaces()
+		if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+			return s.error(ErrMalformedJSON)
+		}
+		s.pos++
+		if err = s.value(); err != nil {
+			return err
+		}
+		s.path = s.path[:len(s.path)-1]
+
+		s.skipSpaces()
+		if s.pos >= len(s.data) {
+			return s.error(ErrMalformedJSON)
+		}
+		switch s.data[s.pos] {
+		case ',':
+			s.pos++
+		case '}':
+			s.pos++
+			return nil
+		default:
+			return s.error(ErrMalformedJSON)
+		}
+	}
+}
+
+func (s *strictScanner) array() error {
+	s.pos++ // [
+	s.skipSpaces()
+	if s.pos < len(s.data) && s.data[s.pos] == ']' {
+		s.pos++
+		return nil
+	}
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	for i := 0; ; i++ {
+		s.path = s.path.child(i)
+		if err := s.value(); err != nil {
+			return err
+		}
+		s.path = s.path[:len(s.path)-1]
+
+		s.skipSpaces()
+		if s.pos >= len(s.data) {
+			return s.error(ErrMalformedJSON)
+		}
+		switch s.data[s.pos] {
+		case ',':
+			s.pos++
+		case ']':
+			s.pos++
+			return nil
+		default:
+			return s.error(ErrMalformedJSON)
+		}
+	}
+}
+
+// string scans a string and returns its decoded value.
+func (s *strictScanner) string() (string, error) {
+	s.pos++ // "
+	var b strings.Builder
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return b.String(), nil
+		case c < 0x20:
+			return "", s.error(ErrMalformedJSON)
+		case c == '\\':
+			r, err := s.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		case c < utf8.RuneSelf:
+			b.WriteByte(c)
+			s.pos++
+		default:
+			r, size := utf8.DecodeRune(s.data[s.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return "", s.error(ErrInvalidUTF8)
+			}
+			b.WriteRune(r)
+			s.pos += size
+		}
+	}
+	return "", s.error(ErrMalformedJSON)
+}
+
+// escape scans an escape sequence of a string, surrogate pairs are decoded together.
+func (s *strictScanner) escape() (rune, error) {
+	if s.pos+1 >= len(s.data) {
+		return 0, s.error(ErrMalformedJSON)
+	}
+	switch s.data[s.pos+1] {
+	case '"', '\\', '/':
+		s.pos += 2
+		return rune(s.data[s.pos-1]), nil
+	case 'b':
+		s.pos += 2
+		return '\b', nil
+	case 'f':
+		s.pos += 2
+		return '\f', nil
+	case 'n':
+		s.pos += 2
+		return '\n', nil
+	case 'r':
+		s.pos += 2
+		return '\r', nil
+	case 't':
+		s.pos += 2
+		return '\t', nil
+	case 'u':
+	default:
+		return 0, s.error(ErrMalformedJSON)
+	}
+
+	start := s.pos
+	r, ok := s.hex()
+	if !ok {
+		return 0, s.error(ErrMalformedJSON)
+	}
+	if !utf16.IsSurrogate(r) {
+		return r, nil
+	}
+	if r < 0xDC00 {
+		// a high surrogate must be followed by a low one
+		if low, ok := s.hex(); ok && low >= 0xDC00 && low <= 0xDFFF {
+			return utf16.DecodeRune(r, low), nil
+		}
+	}
+	s.pos = start
+	return 0, s.error(ErrLoneSurrogate)
+}
+
+// hex scans a \uXXXX escape sequence.
+func (s *strictScanner) hex() (rune, bool) {
+	if s.pos+6 > len(s.data) || s.data[s.pos] != '\\' || s.data[s.pos+1] != 'u' {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(string(s.data[s.pos+2:s.pos+6]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	s.pos += 6
+	return rune(r), true
+}
+
+func (s *strictScanner) literal(lit string) error {
+	if !bytes.HasPrefix(s.data[s.pos:], []byte(lit)) {
+		return s.error(ErrMalformedJSON)
+	}
+	s.pos += len(lit)
+	return nil
+}
+
+func (s *strictScanner) number() error {
+	start := s.pos
+	digits := func() int {
+		n := 0
+		for s.pos < len(s.data) && s.data[s.pos] >= '0' && s.data[s.pos] <= '9' {
+			s.pos++
+			n++
+		}
+		return n
+	}
+	if s.data[s.pos] == '-' {
+		s.pos++
+	}
+	if s.pos < len(s.data) && s.data[s.pos] == '0' {
+		s.pos++
+	} else if digits() == 0 {
+		s.pos = start
+		return s.error(ErrMalformedJSON)
+	}
+	if s.pos < len(s.data) && s.data[s.pos] == '.' {
+		s.pos++
+		if digits() == 0 {
+			return s.error(ErrMalformedJSON)
+		}
+	}
+	if s.pos < len(s.data) && (s.data[s.pos] == 'e' || s.data[s.pos] == 'E') {
+		s.pos++
+		if s.pos < len(s.data) && (s.data[s.pos] == '+' || s.data[s.pos] == '-') {
+			s.pos++
+		}
+		if digits() == 0 {
+			return s.error(ErrMalformedJSON)
+		}
+	}
+	return nil
+}
+
+// knownFields returns a function that reports weather a JSON object key has rules, or the rules of a schema of
+// the schema rules of its parents, see schemaRules.
+// Keys of objects that have no rules for their children are free-form, so they're always known.
+func knownFields(rules Rules) func(p Path) bool {
+	rules = schemaRules(rules)
+	v := createNewValidation(rules)
+	hasChildRules := func(p Path) bool {
+		prefix := p.prefix()
+		for k := range rules {
+			if strings.HasPrefix(k, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(p Path) bool {
+		parent := p[:len(p)-1]
+		if len(parent) > 0 && !hasChildRules(parent) {
+			return true
+		}
+		name := p.String()
+		_, ok := rules[name]
+		return ok || len(v.getNestedRules(name, parent.String())) > 0 || hasChildRules(p)
+	}
+}
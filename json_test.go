@@ -0,0 +1,188 @@
+package valdn
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_JSONError(t *testing.T) {
+	err := &JSONError{Offset: 12, Pointer: "/user/name", Err: ErrDuplicateKey}
+	if got, want := err.Error(), `duplicate key at offset 12 ("/user/name")`; got != want {
+		t.Errorf("JSONError.Error() = %v, want %v", got, want)
+	}
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("JSONError doesn't wrap %v", ErrDuplicateKey)
+	}
+}
+
+func Test_checkStrictJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		known       func(p Path) bool
+		maxDepth    int
+		wantErr     error
+		wantOffset  int
+		wantPointer string
+	}{
+		{
+			name:    "test checkStrictJSON with valid json",
+			data:    ` {"name":"Narmer","age":30,"tags":["a","é😀"],"ok":true,"x":null,"f":-1.5e+3} `,
+			wantErr: nil,
+		},
+		{
+			name:    "test checkStrictJSON with scalar",
+			data:    `"Narmer"`,
+			wantErr: nil,
+		},
+		{
+			name:        "test checkStrictJSON with duplicate key",
+			data:        `{"user":{"name":"a","name":"b"}}`,
+			wantErr:     ErrDuplicateKey,
+			wantOffset:  20,
+			wantPointer: "/user/name",
+		},
+		{
+			name:        "test checkStrictJSON with duplicate escaped key",
+			data:        `{"name":"a","n\u0061me":"b"}`,
+			wantErr:     ErrDuplicateKey,
+			wantOffset:  12,
+			wantPointer: "/name",
+		},
+		{
+			name:        "test checkStrictJSON with trailing data",
+			data:        `{"name":"a"} {"name":"b"}`,
+			wantErr:     ErrTrailingData,
+			wantOffset:  13,
+			wantPointer: "",
+		},
+		{
+			name:        "test checkStrictJSON with invalid utf-8",
+			data:        "{\"tags\":[\"a\",\"b\xff\"]}",
+			wantErr:     ErrInvalidUTF8,
+			wantOffset:  15,
+			wantPointer: "/tags/1",
+		},
+		{
+			name:        "test checkStrictJSON with lone high surrogate",
+			data:        `{"name":"\ud83d"}`,
+			wantErr:     ErrLoneSurrogate,
+			wantOffset:  9,
+			wantPointer: "/name",
+		},
+		{
+			name:        "test checkStrictJSON with lone low surrogate",
+			data:        `{"name":"a\ude00b"}`,
+			wantErr:     ErrLoneSurrogate,
+			wantOffset:  10,
+			wantPointer: "/name",
+		},
+		{
+			name:        "test checkStrictJSON with malformed json",
+			data:        `{"name":"a",}`,
+			wantErr:     ErrMalformedJSON,
+			wantOffset:  12,
+			wantPointer: "",
+		},
+		{
+			name:        "test checkStrictJSON with malformed number",
+			data:        `{"age":01}`,
+			wantErr:     ErrMalformedJSON,
+			wantOffset:  8,
+			wantPointer: "",
+		},
+		{
+			name:        "test checkStrictJSON with unknown field",
+			data:        `{"name":"a","role":"admin"}`,
+			known:       func(p Path) bool { return p[0] == "name" },
+			wantErr:     ErrUnknownField,
+			wantOffset:  12,
+			wantPointer: "/role",
+		},
+		{
+			name:        "test checkStrictJSON with max depth",
+			data:        `{"a":[[1]],"b":[2]}`,
+			maxDepth:    2,
+			wantErr:     ErrTooDeep,
+			wantOffset:  7,
+			wantPointer: "/a/0",
+		},
+		{
+			name:     "test checkStrictJSON with empty values at max depth",
+			data:     `{"a":[[],{}]}`,
+			maxDepth: 2,
+			wantErr:  nil,
+		},
+		{
+			name:        "test checkStrictJSON with deep nesting and no max depth",
+			data:        strings.Repeat("[", 5_000_000),
+			wantErr:     ErrTooDeep,
+			wantOffset:  maxJSONDepth + 1,
+			wantPointer: strings.Repeat("/0", maxJSONDepth),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStrictJSON([]byte(tt.data), tt.known, tt.maxDepth)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("checkStrictJSON() error = %v, want nil", err)
+				}
+				return
+			}
+			var jsonErr *JSONError
+			if !errors.As(err, &jsonErr) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkStrictJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if jsonErr.Offset != tt.wantOffset || jsonErr.Pointer != tt.wantPointer {
+				t.Errorf("checkStrictJSON() error at %v %q, want %v %q", jsonErr.Offset, jsonErr.Pointer, tt.wantOffset, tt.wantPointer)
+			}
+		})
+	}
+}
+
+func Test_knownFields(t *testing.T) {
+	known := knownFields(Rules{
+		"name":           {"required"},
+		"address.city":   {"required"},
+		"tags.*":         {"kind:string"},
+		"meta":           {"kind:map"},
+		"items.0.sku":    {"required"},
+		"settings":       {},
+		"settings.theme": {"in:dark,light"},
+	})
+	tests := []struct {
+		path Path
+		want bool
+	}{
+		{path: Path{"name"}, want: true},
+		{path: Path{"role"}, want: false},
+		{path: Path{"address"}, want: true},
+		{path: Path{"address", "city"}, want: true},
+		{path: Path{"address", "zip"}, want: false},
+		{path: Path{"tags"}, want: true},
+		{path: Path{"meta", "anything"}, want: true},
+		{path: Path{"items", 0, "sku"}, want: true},
+		{path: Path{"items", 0, "qty"}, want: false},
+		{path: Path{"settings", "lang"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run("test knownFields with "+JSONPointerPath(tt.path), func(t *testing.T) {
+			if got := known(tt.path); got != tt.want {
+				t.Errorf("knownFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateJSON_deepNesting(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		var jsonErr *JSONError
+		if !errors.As(err, &jsonErr) || !errors.Is(err, ErrTooDeep) {
+			t.Errorf("ValidateJSON() panic = %v, want JSONError of %v", err, ErrTooDeep)
+		}
+	}()
+	ValidateJSON(strings.Repeat("[", 5_000_000), Rules{}, WithStrictJSON(), WithLimits(Limits{MaxDepth: 10}))
+}
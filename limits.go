@@ -0,0 +1,196 @@
+package valdn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrBodyTooLarge is wrapped by RequestError when the request body is larger than Limits.MaxBodyBytes.
+	ErrBodyTooLarge = errors.New("request body too large")
+	// ErrRecordTooLarge is returned when a record of ValidateNDJSON is larger than Limits.MaxBodyBytes.
+	ErrRecordTooLarge = errors.New("record too large")
+	// ErrTooDeep is returned when a value is nested deeper than Limits.MaxDepth.
+	ErrTooDeep = errors.New("value nested too deep")
+	// ErrTooManyKeys is returned when an object has more keys than Limits.MaxKeys.
+	ErrTooManyKeys = errors.New("too many object keys")
+	// ErrArrayTooLong is returned when an array has more items than Limits.MaxArrayLen.
+	ErrArrayTooLong = errors.New("array too long")
+	// ErrTooManyFormFields is wrapped by RequestError when a form has more fields than Limits.MaxFormFields.
+	ErrTooManyFormFields = errors.New("too many form fields")
+	// ErrTooManyFiles is wrapped by RequestError when a multipart body has more files than Limits.MaxFiles.
+	ErrTooManyFiles = errors.New("too many files")
+	// ErrTooManyParts is wrapped by RequestError when a multipart body has more parts than Limits.MaxParts.
+	ErrTooManyParts = errors.New("too many multipart parts")
+)
+
+// Limits bounds the size and the shape of validated documents, so a single request can't make validation expensive.
+// Zero fields are not limited.
+type Limits struct {
+	// MaxBodyBytes is the max size of a request body or a document, and the max size of every record of ValidateNDJSON.
+	MaxBodyBytes int64
+	// MaxDepth is the max nesting depth of objects and arrays, values of the root object are at depth 1.
+	// JSON and XML documents are nested 10000 levels at most, and url params and form fields 32 levels, if it's zero.
+	MaxDepth int
+	// MaxKeys is the max number of keys of one object.
+	MaxKeys int
+	// MaxArrayLen is the max number of items of one array.
+	MaxArrayLen int
+	// MaxFormFields is the max number of values of a url encoded or multipart form, files not included,
+	// and the max number of url query params of a request. They're counted before they're parsed.
+	MaxFormFields int
+	// MaxParts is the max number of parts (values and files) of a multipart body.
+	MaxParts int
+	// MaxFiles is the max number of files of a multipart body.
+	MaxFiles int
+	// MaxRows is the max number of rows of a CSV document, the header not included.
+	MaxRows int
+	// MaxMemory is the max number of bytes of multipart files stored in memory, the rest are stored in temporary files.
+	// It's 32 MB if zero.
+	MaxMemory int64
+}
+
+// limitStatus returns the HTTP status code that fits a limit error, or 0 if err is not one.
+func limitStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrBodyTooLarge), errors.Is(err, ErrTooManyFormFields), errors.Is(err, ErrTooManyParts),
+		errors.Is(err, ErrTooManyFiles), errors.Is(err, ErrTooManyRows):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrTooDeep), errors.Is(err, ErrTooManyKeys), errors.Is(err, ErrArrayTooLong):
+		return http.StatusUnprocessableEntity
+	}
+	return 0
+}
+
+// maxMemory returns the max number of bytes of multipart files stored in memory.
+func (l Limits) maxMemory() int64 {
+	if l.MaxMemory > 0 {
+		return l.MaxMemory
+	}
+	return defaultMaxMemory
+}
+
+// checkBodySize returns ErrBodyTooLarge if size is larger than MaxBodyBytes.
+func (l Limits) checkBodySize(size int64) error {
+	if l.MaxBodyBytes > 0 && size > l.MaxBodyBytes {
+		return fmt.Errorf("%w: more than %v bytes", ErrBodyTooLarge, l.MaxBodyBytes)
+	}
+	return nil
+}
+
+// limitReader returns r, or a reader of r whose reads fail with ErrBodyTooLarge once it reads more than MaxBodyBytes.
+func (l Limits) limitReader(r io.Reader) io.Reader {
+	if l.MaxBodyBytes <= 0 {
+		return r
+	}
+	return &bodyReader{r: r, l: l}
+}
+
+// bodyReader counts the bytes read from r to check them by the body size limit of l.
+type bodyReader struct {
+	r    io.Reader
+	l    Limits
+	read int64
+	err  error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+	if b.err = b.l.checkBodySize(b.read); b.err != nil {
+		// bytes over the limit are not returned
+		n -= int(b.read - b.l.MaxBodyBytes)
+		if n < 0 {
+			n = 0
+		}
+		return n, b.err
+	}
+	return n, err
+}
+
+// checkValue returns an error if val or one of its nested values exceeds MaxDepth, MaxKeys or MaxArrayLen.
+// name is the name of val, and depth is its nesting depth.
+func (l Limits) checkValue(name string, val interface{}, depth int) error {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		if l.MaxKeys > 0 && len(v) > l.MaxKeys {
+			return fmt.Errorf("%w: %v has more than %v keys", ErrTooManyKeys, rootName(name), l.MaxKeys)
+		}
+		if len(v) > 0 && l.MaxDepth > 0 && depth+1 > l.MaxDepth {
+			return fmt.Errorf("%w: %v is nested more than %v levels", ErrTooDeep, rootName(name), l.MaxDepth)
+		}
+		for k, item := range v {
+			if err := l.checkValue(joinName(name, k), item, depth+1); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if l.MaxArrayLen > 0 && len(v) > l.MaxArrayLen {
+			return fmt.Errorf("%w: %v has more than %v items", ErrArrayTooLong, rootName(name), l.MaxArrayLen)
+		}
+		if len(v) > 0 && l.MaxDepth > 0 && depth+1 > l.MaxDepth {
+			return fmt.Errorf("%w: %v is nested more than %v levels", ErrTooDeep, rootName(name), l.MaxDepth)
+		}
+		for i, item := range v {
+			if err := l.checkValue(joinName(name, toString(i)), item, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkFormFields returns ErrTooManyFormFields if a form has more values than MaxFormFields.
+func (l Limits) checkFormFields(fields map[string][]string) error {
+	if l.MaxFormFields <= 0 {
+		return nil
+	}
+	n := 0
+	for _, v := range fields {
+		n += len(v)
+	}
+	if n > l.MaxFormFields {
+		return fmt.Errorf("%w: more than %v", ErrTooManyFormFields, l.MaxFormFields)
+	}
+	return nil
+}
+
+// maxParamDepth is the max nesting depth of url params and form fields if Limits.MaxDepth is not set,
+// e.g. a[b][c] is nested 3 levels.
+const maxParamDepth = 32
+
+// checkParamDepth returns ErrTooDeep if the param of segments (see splitParamKey) is nested more than MaxDepth levels,
+// or maxParamDepth if MaxDepth is not set.
+func (l Limits) checkParamDepth(segments []string) error {
+	max := l.MaxDepth
+	if max <= 0 {
+		max = maxParamDepth
+	}
+	if len(segments) > max {
+		// named like checkValue names them, by the value whose items are too deep
+		return fmt.Errorf("%w: %v is nested more than %v levels", ErrTooDeep, strings.Join(segments[:max], "."), max)
+	}
+	return nil
+}
+
+// joinName joins parent name and child name with a dot, the root object has no name.
+func joinName(parent string, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+// rootName returns name, or RootName if it's the name of the root object.
+func rootName(name string) string {
+	if name == "" {
+		return RootName
+	}
+	return name
+}
@@ -0,0 +1,149 @@
+package valdn
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_limitStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "test limitStatus with body too large", err: ErrBodyTooLarge, want: http.StatusRequestEntityTooLarge},
+		{name: "test limitStatus with too many parts", err: fmt.Errorf("%w: more than 2", ErrTooManyParts), want: http.StatusRequestEntityTooLarge},
+		{name: "test limitStatus with too many form fields", err: ErrTooManyFormFields, want: http.StatusRequestEntityTooLarge},
+		{name: "test limitStatus with too deep", err: ErrTooDeep, want: http.StatusUnprocessableEntity},
+		{name: "test limitStatus with too many keys", err: ErrTooManyKeys, want: http.StatusUnprocessableEntity},
+		{name: "test limitStatus with array too long", err: ErrArrayTooLong, want: http.StatusUnprocessableEntity},
+		{name: "test limitStatus with other error", err: errors.New("bla"), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitStatus(tt.err); got != tt.want {
+				t.Errorf("limitStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Limits_maxMemory(t *testing.T) {
+	if got := (Limits{}).maxMemory(); got != defaultMaxMemory {
+		t.Errorf("maxMemory() = %v, want %v", got, defaultMaxMemory)
+	}
+	if got := (Limits{MaxMemory: 1024}).maxMemory(); got != 1024 {
+		t.Errorf("maxMemory() = %v, want %v", got, 1024)
+	}
+}
+
+func Test_Limits_checkBodySize(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		size    int64
+		wantErr error
+	}{
+		{name: "test checkBodySize without limit", limits: Limits{}, size: 1 << 30, wantErr: nil},
+		{name: "test checkBodySize under limit", limits: Limits{MaxBodyBytes: 10}, size: 10, wantErr: nil},
+		{name: "test checkBodySize over limit", limits: Limits{MaxBodyBytes: 10}, size: 11, wantErr: ErrBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.checkBodySize(tt.size); !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("checkBodySize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_Limits_checkValue(t *testing.T) {
+	val := map[string]interface{}{
+		"user":  map[string]interface{}{"name": "Narmer", "address": map[string]interface{}{"city": "Tiba"}},
+		"tags":  []interface{}{"a", "b", "c"},
+		"empty": map[string]interface{}{},
+	}
+	tests := []struct {
+		name    string
+		limits  Limits
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "test checkValue without limits",
+			limits:  Limits{},
+			wantErr: nil,
+		},
+		{
+			name:    "test checkValue within limits",
+			limits:  Limits{MaxDepth: 3, MaxKeys: 3, MaxArrayLen: 3},
+			wantErr: nil,
+		},
+		{
+			name:    "test checkValue with too deep value",
+			limits:  Limits{MaxDepth: 2},
+			wantErr: ErrTooDeep,
+			wantMsg: "value nested too deep: user.address is nested more than 2 levels",
+		},
+		{
+			name:    "test checkValue with too many keys",
+			limits:  Limits{MaxKeys: 2},
+			wantErr: ErrTooManyKeys,
+			wantMsg: "too many object keys: $ has more than 2 keys",
+		},
+		{
+			name:    "test checkValue with too long array",
+			limits:  Limits{MaxArrayLen: 2},
+			wantErr: ErrArrayTooLong,
+			wantMsg: "array too long: tags has more than 2 items",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.checkValue("", val, 0)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("checkValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("checkValue() error = %v, want %v", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func Test_Limits_checkFormFields(t *testing.T) {
+	fields := map[string][]string{"lang": {"go", "python"}, "page": {"2"}}
+	tests := []struct {
+		name    string
+		limits  Limits
+		wantErr error
+	}{
+		{name: "test checkFormFields without limit", limits: Limits{}, wantErr: nil},
+		{name: "test checkFormFields under limit", limits: Limits{MaxFormFields: 3}, wantErr: nil},
+		{name: "test checkFormFields over limit", limits: Limits{MaxFormFields: 2}, wantErr: ErrTooManyFormFields},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.checkFormFields(fields); !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("checkFormFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_bodyReader_Read(t *testing.T) {
+	r := Limits{MaxBodyBytes: 3}.limitReader(strings.NewReader("abcdef"))
+	p := make([]byte, 2)
+	for i, want := range []int{2, 1, 0, 0} {
+		n, err := r.Read(p)
+		if n != want {
+			t.Errorf("Read() #%v n = %v, want %v", i, n, want)
+		}
+		if i > 0 && !errors.Is(err, ErrBodyTooLarge) {
+			t.Errorf("Read() #%v error = %v, want %v", i, err, ErrBodyTooLarge)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package valdn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type (
+	// Routes maps net/http.ServeMux patterns (e.g. "POST /users/{id}") to the rules of that route.
+	Routes map[string]Rules
+
+	// ErrorResponder writes the response of a request that failed validation.
+	// status is 422 if the request is invalid, or the status of RequestError if it couldn't be parsed.
+	ErrorResponder func(w http.ResponseWriter, r *http.Request, status int, errs Errors)
+)
+
+type valuesCtxKey struct{}
+
+// defaultErrorResponder writes errs as a JSON object with status.
+func defaultErrorResponder(w http.ResponseWriter, _ *http.Request, status int, errs Errors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errs)
+}
+
+// ValuesFromContext returns the request values parsed and validated by Middleware.
+// It returns nil if the request didn't pass through Middleware.
+func ValuesFromContext(ctx context.Context) map[string]interface{} {
+	m, _ := ctx.Value(valuesCtxKey{}).(map[string]interface{})
+	return m
+}
+
+// Middleware validates every request by rules before it reaches the next handler.
+// Invalid requests are answered with 422, unparsable requests with the status of RequestError (400, 415...).
+// Valid requests are passed to the next handler with their values stored in the request context, see ValuesFromContext.
+// If routes are set using WithRoutes, requests matching a route are validated by its rules
+// and the rest are validated by rules, or passed as they are if rules is nil.
+// It panics if one of the rules is not registered.
+func Middleware(rules Rules, opts ...Option) func(http.Handler) http.Handler {
+	c := newConfig(opts)
+	return func(next http.Handler) http.Handler {
+		fallback := validationHandler(rules, c, next)
+		if rules == nil {
+			fallback = next
+		}
+		if len(c.routes) == 0 {
+			return fallback
+		}
+
+		// the mux only selects the rules of a route, its own responses (e.g. redirects of /items to /items/)
+		// are left to next
+		mux := http.NewServeMux()
+		for pattern, routeRules := range c.routes {
+			mux.Handle(pattern, routeHandler{validationHandler(routeRules, c, next)})
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h, _ := mux.Handler(r); !isRouteHandler(h) {
+				fallback.ServeHTTP(w, r)
+				return
+			}
+			// serving through the mux sets the route's path values on r
+			mux.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeHandler is the handler of a route of Middleware.
+type routeHandler struct {
+	http.Handler
+}
+
+// isRouteHandler reports weather h is the handler of a route, rather than a handler of the mux like redirects.
+func isRouteHandler(h http.Handler) bool {
+	_, ok := h.(routeHandler)
+	return ok
+}
+
+func validationHandler(rules Rules, c *config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m, errs, reqErr := tryValidateRequest(r, rules, c)
+		if reqErr != nil {
+			c.errorResponder(w, r, reqErr.Status, Errors{"request": reqErr.Error()})
+			return
+		}
+		if len(errs) > 0 {
+			c.errorResponder(w, r, http.StatusUnprocessableEntity, errs)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), valuesCtxKey{}, m)))
+	})
+}
+
+// tryValidateRequest validates r by rules and recovers RequestError panics raised while parsing it.
+// Other panics (e.g. unregistered rules) are raised again.
+func tryValidateRequest(r *http.Request, rules Rules, c *config) (m map[string]interface{}, errs Errors, reqErr *RequestError) {
+	defer func() {
+		if e := recover(); e != nil {
+			re, ok := e.(*RequestError)
+			if !ok {
+				panic(e)
+			}
+			reqErr = re
+		}
+	}()
+	m, errs = validateRequest(r, rules, c)
+	return m, errs, nil
+}
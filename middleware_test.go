@@ -0,0 +1,313 @@
+package valdn
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ValuesFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]interface{}
+	}{
+		{
+			name: "test ValuesFromContext with values",
+			ctx:  context.WithValue(context.Background(), valuesCtxKey{}, map[string]interface{}{"lang": "go"}),
+			want: map[string]interface{}{"lang": "go"},
+		},
+		{
+			name: "test ValuesFromContext without values",
+			ctx:  context.Background(),
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValuesFromContext(tt.ctx); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValuesFromContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Middleware(t *testing.T) {
+	unsupportedRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("--xxx--"))
+		r.Header.Set("Content-Type", "multipart/form-data")
+		return r
+	}
+	chunkedRequest := func() *http.Request {
+		r := jsonRequest()
+		r.ContentLength = -1
+		return r
+	}
+	routes := Routes{
+		"POST /users/{id}": {"id": {"required"}, "name": {"required"}},
+		"GET /users":       {"lang": {"required", "in:go"}},
+		"PUT /users/{id}":  {"path.id": {"required", "int"}},
+		"/items/":          {"sku": {"required"}},
+	}
+	responder := func(w http.ResponseWriter, r *http.Request, status int, errs Errors) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("custom"))
+	}
+	tests := []struct {
+		name       string
+		rules      Rules
+		opts       []Option
+		req        *http.Request
+		wantStatus int
+		wantErrs   Errors
+		wantValues map[string]interface{}
+		wantBody   string
+		wantNext   bool
+	}{
+		{
+			name:       "test Middleware with valid request",
+			rules:      Rules{"lang": {"required"}},
+			req:        jsonRequest(),
+			wantStatus: http.StatusOK,
+			wantValues: map[string]interface{}{"lang": "go"},
+		},
+		{
+			name:       "test Middleware with invalid request",
+			rules:      Rules{"lang": {"required", "minLen:3"}},
+			req:        jsonRequest(),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"lang": "lang's length must be greater than or equal: 3"},
+		},
+		{
+			name:       "test Middleware with malformed body",
+			rules:      Rules{"lang": {"required"}},
+			req:        emptyJSONRequest(),
+			wantStatus: http.StatusBadRequest,
+			wantErrs:   Errors{"request": "unexpected end of JSON input"},
+		},
+		{
+			name:       "test Middleware with multipart request without boundary",
+			rules:      Rules{"lang": {"required"}},
+			req:        unsupportedRequest(),
+			wantStatus: http.StatusBadRequest,
+			wantErrs:   Errors{"request": "no multipart boundary param in Content-Type"},
+		},
+		{
+			name:       "test Middleware with not allowed content type",
+			rules:      Rules{"lang": {"required"}},
+			opts:       []Option{WithContentTypes("application/json")},
+			req:        urlencodedRequest(),
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantErrs:   Errors{"request": "unsupported media type: application/x-www-form-urlencoded"},
+		},
+		{
+			name:       "test Middleware with duplicate key in strict mode",
+			rules:      Rules{"lang": {"required"}},
+			opts:       []Option{WithStrictJSON()},
+			req:        contentTypeRequest(`{"lang":"go","lang":"rust"}`, "application/json"),
+			wantStatus: http.StatusBadRequest,
+			wantErrs:   Errors{"request": `duplicate key at offset 13 ("/lang")`},
+		},
+		{
+			name:       "test Middleware with too large body",
+			rules:      Rules{"lang": {"required"}},
+			opts:       []Option{WithLimits(Limits{MaxBodyBytes: 5})},
+			req:        jsonRequest(),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErrs:   Errors{"request": "request body too large: more than 5 bytes"},
+		},
+		{
+			name:       "test Middleware with too large body of unknown length",
+			rules:      Rules{"lang": {"required"}},
+			opts:       []Option{WithLimits(Limits{MaxBodyBytes: 5})},
+			req:        chunkedRequest(),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErrs:   Errors{"request": "request body too large: more than 5 bytes"},
+		},
+		{
+			name:       "test Middleware with too many form fields",
+			rules:      Rules{"lang": {"required"}},
+			opts:       []Option{WithLimits(Limits{MaxFormFields: 1})},
+			req:        multipleParamsURLEncodedRequest(),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErrs:   Errors{"request": "too many form fields: more than 1"},
+		},
+		{
+			name:       "test Middleware with too many multipart parts",
+			rules:      Rules{"field": {"required"}},
+			opts:       []Option{WithLimits(Limits{MaxParts: 3})},
+			req:        twoValuesTwoFilesFormDataRequest(),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErrs:   Errors{"request": "too many multipart parts: more than 3"},
+		},
+		{
+			name:       "test Middleware with too deep form field",
+			rules:      Rules{"a": {"required"}},
+			opts:       []Option{WithLimits(Limits{MaxDepth: 2})},
+			req:        contentTypeRequest("a[b][c]=d", "application/x-www-form-urlencoded"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"request": "value nested too deep: a.b is nested more than 2 levels"},
+		},
+		{
+			name:       "test Middleware with custom error responder",
+			rules:      Rules{"value": {"required"}},
+			opts:       []Option{WithErrorResponder(responder)},
+			req:        jsonRequest(),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "custom",
+		},
+		{
+			name:       "test Middleware with matched route and missing values",
+			opts:       []Option{WithRoutes(routes)},
+			req:        httptest.NewRequest(http.MethodPost, "/users/5", strings.NewReader("")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"id": "id is required", "name": "name is required"},
+		},
+		{
+			name:       "test Middleware with matched route",
+			opts:       []Option{WithRoutes(routes)},
+			req:        httptest.NewRequest(http.MethodGet, "/users?lang=go", strings.NewReader("")),
+			wantStatus: http.StatusOK,
+			wantValues: map[string]interface{}{"lang": "go"},
+		},
+		{
+			name:       "test Middleware with matched route and invalid path value",
+			opts:       []Option{WithRoutes(routes), WithNamespaces()},
+			req:        httptest.NewRequest(http.MethodPut, "/users/abc", strings.NewReader("")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"path.id": "path.id must be an integer"},
+		},
+		{
+			name:       "test Middleware with unmatched route and nil rules",
+			opts:       []Option{WithRoutes(routes)},
+			req:        httptest.NewRequest(http.MethodGet, "/posts", strings.NewReader("")),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "test Middleware with unmatched route and fallback rules",
+			rules:      Rules{"page": {"required"}},
+			opts:       []Option{WithRoutes(routes)},
+			req:        httptest.NewRequest(http.MethodGet, "/posts", strings.NewReader("")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"page": "page is required"},
+		},
+		{
+			name:       "test Middleware with too many object keys before malformed json",
+			rules:      Rules{"a": {"required"}},
+			opts:       []Option{WithLimits(Limits{MaxKeys: 2})},
+			req:        contentTypeRequest(`{"a":1,"b":2,"c":3,`, "application/json"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"request": "too many object keys: $ has more than 2 keys"},
+		},
+		{
+			name:  "test Middleware with too many multipart parts before malformed part",
+			rules: Rules{"a": {"required"}},
+			opts:  []Option{WithLimits(Limits{MaxParts: 1})},
+			req: contentTypeRequest("--b\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n"+
+				"--b\r\nContent-Disposition: form-data; name=\"b\"\r\n\r\n2\r\n--b\r\nbla", "multipart/form-data; boundary=b"),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErrs:   Errors{"request": "too many multipart parts: more than 1"},
+		},
+		{
+			name:  "test Middleware with too many multipart form fields",
+			rules: Rules{"a": {"required"}},
+			opts:  []Option{WithLimits(Limits{MaxFormFields: 1})},
+			req: contentTypeRequest("--b\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n"+
+				"--b\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n2\r\n--b--\r\n", "multipart/form-data; boundary=b"),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErrs:   Errors{"request": "too many form fields: more than 1"},
+		},
+		{
+			name:       "test Middleware with too many url params",
+			rules:      Rules{"a": {"required"}},
+			opts:       []Option{WithLimits(Limits{MaxFormFields: 2})},
+			req:        httptest.NewRequest(http.MethodGet, "/?a=1&a=2&b=3", strings.NewReader("")),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantErrs:   Errors{"request": "too many form fields: more than 2"},
+		},
+		{
+			name:       "test Middleware with too deep url param",
+			rules:      Rules{"a": {"required"}},
+			req:        httptest.NewRequest(http.MethodGet, "/?a"+strings.Repeat("[x]", 5000)+"=1", strings.NewReader("")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"request": "value nested too deep: a" + strings.Repeat(".x", maxParamDepth-1) + " is nested more than 32 levels"},
+		},
+		{
+			name:       "test Middleware with too deep url encoded field",
+			rules:      Rules{"a": {"required"}},
+			req:        contentTypeRequest("b=1&a"+strings.Repeat("%5Bx%5D", 5000)+"=1", "application/x-www-form-urlencoded"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"request": "value nested too deep: a" + strings.Repeat(".x", maxParamDepth-1) + " is nested more than 32 levels"},
+		},
+		{
+			name:  "test Middleware with too deep multipart field",
+			rules: Rules{"a": {"required"}},
+			req: contentTypeRequest("--b\r\nContent-Disposition: form-data; name=\"a"+strings.Repeat("[x]", 5000)+"\"\r\n\r\n1\r\n--b--\r\n",
+				"multipart/form-data; boundary=b"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"request": "value nested too deep: a" + strings.Repeat(".x", maxParamDepth-1) + " is nested more than 32 levels"},
+		},
+		{
+			name:       "test Middleware with route redirected by the mux",
+			opts:       []Option{WithRoutes(routes)},
+			req:        httptest.NewRequest(http.MethodGet, "/items", strings.NewReader("")),
+			wantStatus: http.StatusOK,
+			wantNext:   true,
+		},
+		{
+			name:       "test Middleware with route of subtree",
+			opts:       []Option{WithRoutes(routes)},
+			req:        httptest.NewRequest(http.MethodGet, "/items/5", strings.NewReader("")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrs:   Errors{"sku": "sku is required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotValues map[string]interface{}
+			gotNext := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotNext = true
+				gotValues = ValuesFromContext(r.Context())
+			})
+			w := httptest.NewRecorder()
+			Middleware(tt.rules, tt.opts...)(next).ServeHTTP(w, tt.req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Middleware() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if tt.wantNext && !gotNext {
+				t.Errorf("Middleware() didn't call next")
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("Middleware() body = %v, want %v", w.Body.String(), tt.wantBody)
+			}
+			if tt.wantErrs != nil {
+				var errs Errors
+				if err := json.Unmarshal(w.Body.Bytes(), &errs); err != nil {
+					t.Fatalf("Middleware() body is not json: %v", w.Body.String())
+				}
+				if !reflect.DeepEqual(errs, tt.wantErrs) {
+					t.Errorf("Middleware() errors = %v, want %v", errs, tt.wantErrs)
+				}
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("Middleware() values = %v, want %v", gotValues, tt.wantValues)
+			}
+		})
+	}
+}
+
+func Test_Middleware_panicsWithUnknownRule(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("Middleware() didn't panic with unknown rule")
+		}
+	}()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	Middleware(Rules{"lang": {"bla"}})(next).ServeHTTP(httptest.NewRecorder(), jsonRequest())
+}
@@ -0,0 +1,215 @@
+package valdn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrFileTooLarge is returned by Part.Read when the file is larger than its size rules allow.
+var ErrFileTooLarge = errors.New("file too large")
+
+// streamRules are the file rules ValidateMultipart checks before a file is read.
+var streamRules = []string{"ext", "notExt", "extIn", "extNotIn", "mime", "mimeIn"}
+
+// fileRules are the rules ValidateMultipart checks while a file streams and after it's read.
+var fileRules = append([]string{"file", "size", "sizeMin", "sizeMax", "sizeBetween"}, streamRules...)
+
+// Part is a file of a multipart body, passed to the handler of ValidateMultipart while the body streams.
+// Reading Part reads the file, reads fail with ErrFileTooLarge once the file is larger than its size rules allow.
+type Part struct {
+	// Name is the name of the file in dotted notation, e.g. docs.0.file for docs[0][file] and docs for docs[].
+	Name string
+	// FileHeader holds the file's name and header, its Size is the number of bytes read so far.
+	// It can't be opened, the file can be read only once from Part.
+	FileHeader *multipart.FileHeader
+
+	r       io.Reader
+	maxSize int64
+	err     error
+}
+
+func (p *Part) Read(b []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	n, err := p.r.Read(b)
+	p.FileHeader.Size += int64(n)
+	if p.maxSize >= 0 && p.FileHeader.Size > p.maxSize {
+		p.err = ErrFileTooLarge
+		return n, p.err
+	}
+	return n, err
+}
+
+// partName returns the dotted name of a multipart field, empty brackets are dropped so files of docs[] are named docs.
+func partName(key string) string {
+	segments := splitParamKey(key)
+	for len(segments) > 1 && segments[len(segments)-1] == "" {
+		segments = segments[:len(segments)-1]
+	}
+	return strings.Join(segments, ".")
+}
+
+// partRules returns the file rules of the file named name, its own rules and the rules of its items (name.*),
+// so every file of a field that has many files is checked by them.
+func partRules(v *validation, name string) []string {
+	var rules []string
+	for _, r := range expandAliases(append(v.getFieldRules(name), v.rules[name+".*"]...)) {
+		rName, _ := splitRuleNameAndRuleValue(r)
+		if isIn(rName, fileRules) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// filterRules returns rules whose names are in names.
+func filterRules(rules []string, names []string) []string {
+	var filtered []string
+	for _, r := range expandAliases(rules) {
+		rName, _ := splitRuleNameAndRuleValue(r)
+		if isIn(rName, names) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// maxFileSize returns the max size a file may have by its size rules, or -1 if they don't limit it.
+func maxFileSize(rules []string) int64 {
+	max := int64(-1)
+	for _, r := range expandAliases(rules) {
+		rName, rVal := splitRuleNameAndRuleValue(r)
+		switch rName {
+		case "size", "sizeMax":
+		case "sizeBetween":
+			rVal = rVal[strings.LastIndexByte(rVal, ',')+1:]
+		default:
+			continue
+		}
+		size, err := strconv.ParseInt(rVal, 10, 64)
+		if err != nil {
+			panic(fmt.Errorf("%v must be an integer", rName))
+		}
+		if max < 0 || size < max {
+			max = size
+		}
+	}
+	return max
+}
+
+// ValidateMultipart validates multipart/form-data request by rules while its body streams, so files are never buffered.
+// handle is called with every file after its extension and media type rules pass (ext, notExt, extIn, extNotIn, mime
+// and mimeIn), and it may read the file from Part up to the max size its size rules allow (size, sizeMax, sizeBetween).
+// The rest of the file's rules are checked after handle returns.
+// It stops at the first file that breaks its rules and returns its error, handle should discard files it kept if
+// errors are returned.
+// Form values are validated after the body is read, and they are stored in r.MultipartForm.Value and r.PostForm.
+// It returns the error of handle if it fails.
+// It panics with RequestError if r is not a multipart request, its body is malformed,
+// or it exceeds the limits set using WithLimits (MaxBodyBytes, MaxFiles, MaxParts, MaxFormFields...).
+// It panics if one of the rules is not registered.
+func ValidateMultipart(r *http.Request, rules Rules, handle func(p *Part) error, opts ...Option) (Errors, error) {
+	c := newConfig(opts)
+	l := c.limits
+	if l.MaxBodyBytes > 0 && hasBody(r) {
+		if err := l.checkBodySize(r.ContentLength); err != nil {
+			panic(newRequestError(err))
+		}
+		r.Body = http.MaxBytesReader(nil, r.Body, l.MaxBodyBytes)
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		panic(newRequestError(err))
+	}
+
+	v := createNewValidation(rules)
+	values := make(map[string][]string)
+	fields := make(map[string][]interface{})
+	parts, files := 0, 0
+	valuesSize := int64(0)
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(newRequestError(err))
+		}
+		parts++
+		if l.MaxParts > 0 && parts > l.MaxParts {
+			panic(newRequestError(fmt.Errorf("%w: more than %v", ErrTooManyParts, l.MaxParts)))
+		}
+		key := p.FormName()
+		if key == "" {
+			continue
+		}
+
+		if p.FileName() == "" {
+			b, err := io.ReadAll(io.LimitReader(p, l.maxMemory()-valuesSize+1))
+			if err != nil {
+				panic(newRequestError(err))
+			}
+			valuesSize += int64(len(b))
+			if valuesSize > l.maxMemory() {
+				panic(newRequestError(fmt.Errorf("%w: form values are more than %v bytes", ErrBodyTooLarge, l.maxMemory())))
+			}
+			values[key] = append(values[key], string(b))
+			if err = l.checkFormFields(values); err != nil {
+				panic(newRequestError(err))
+			}
+			continue
+		}
+
+		files++
+		if l.MaxFiles > 0 && files > l.MaxFiles {
+			panic(newRequestError(fmt.Errorf("%w: more than %v", ErrTooManyFiles, l.MaxFiles)))
+		}
+		part := &Part{
+			Name:       partName(key),
+			FileHeader: &multipart.FileHeader{Filename: p.FileName(), Header: p.Header},
+			r:          p,
+		}
+		pRules := partRules(v, part.Name)
+		if err = Validate(part.Name, part.FileHeader, filterRules(pRules, streamRules)); err != nil {
+			return Errors{formatName(nil, part.Name, c.pathFormat): err.Error()}, nil
+		}
+		part.maxSize = maxFileSize(pRules)
+		if err = handle(part); err != nil && !errors.Is(err, ErrFileTooLarge) {
+			return nil, err
+		}
+		// read what handle left of the file, so its size rules can be checked
+		if _, err = io.Copy(io.Discard, part); err != nil && !errors.Is(err, ErrFileTooLarge) {
+			panic(newRequestError(err))
+		}
+		if err = Validate(part.Name, part.FileHeader, pRules); err != nil {
+			return Errors{formatName(nil, part.Name, c.pathFormat): err.Error()}, nil
+		}
+		fields[key] = append(fields[key], part.FileHeader)
+	}
+
+	r.MultipartForm = &multipart.Form{Value: values, File: make(map[string][]*multipart.FileHeader)}
+	r.PostForm = values
+	_ = r.ParseForm()
+
+	for k, vals := range values {
+		fields[k] = append(fields[k], stringSliceToInterface(vals)...)
+	}
+	m, err := decodeFields(fields, rules, l)
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	if err = l.checkValue("", m, 0); err != nil {
+		panic(newRequestError(err))
+	}
+	parseErrs := make(Errors)
+	if !c.lossless {
+		convertReqVals(m, rules, nil, parseErrs)
+	}
+	return validateParsed(m, rules, parseErrs, c), nil
+}
@@ -0,0 +1,292 @@
+package valdn
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testPart struct {
+	name        string
+	fileName    string
+	contentType string
+	content     string
+}
+
+func streamRequest(parts ...testPart) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		if p.fileName == "" {
+			h.Set("Content-Disposition", `form-data; name="`+p.name+`"`)
+		} else {
+			h.Set("Content-Disposition", `form-data; name="`+p.name+`"; filename="`+p.fileName+`"`)
+			h.Set("Content-Type", p.contentType)
+		}
+		pw, _ := w.CreatePart(h)
+		_, _ = pw.Write([]byte(p.content))
+	}
+	_ = w.Close()
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	return r
+}
+
+func Test_Part_Read(t *testing.T) {
+	p := &Part{FileHeader: &multipart.FileHeader{}, r: strings.NewReader("0123456789"), maxSize: 4}
+	b, err := io.ReadAll(p)
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Part.Read() error = %v, want %v", err, ErrFileTooLarge)
+	}
+	if p.FileHeader.Size <= 4 || len(b) != int(p.FileHeader.Size) {
+		t.Errorf("Part.Read() read %v bytes, size %v", len(b), p.FileHeader.Size)
+	}
+
+	p = &Part{FileHeader: &multipart.FileHeader{}, r: strings.NewReader("0123456789"), maxSize: -1}
+	if b, err = io.ReadAll(p); err != nil || string(b) != "0123456789" || p.FileHeader.Size != 10 {
+		t.Errorf("Part.Read() = %v, %v, size %v", string(b), err, p.FileHeader.Size)
+	}
+}
+
+func Test_partName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "avatar", want: "avatar"},
+		{key: "docs[]", want: "docs"},
+		{key: "docs[0][file]", want: "docs.0.file"},
+		{key: "docs.0.file", want: "docs.0.file"},
+	}
+	for _, tt := range tests {
+		t.Run("test partName with "+tt.key, func(t *testing.T) {
+			if got := partName(tt.key); got != tt.want {
+				t.Errorf("partName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_partRules(t *testing.T) {
+	v := createNewValidation(Rules{
+		"avatar":  {"required", "extIn:png,jpg", "sizeMax:10"},
+		"docs":    {"required", "maxLen:2"},
+		"docs.*":  {"mime:application/pdf"},
+		"files.*": {"sizeMax:5"},
+	})
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "avatar", want: []string{"extIn:png,jpg", "sizeMax:10"}},
+		{name: "docs", want: []string{"mime:application/pdf"}},
+		{name: "files.0", want: []string{"sizeMax:5"}},
+		{name: "other", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run("test partRules with "+tt.name, func(t *testing.T) {
+			if got := partRules(v, tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("partRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_filterRules(t *testing.T) {
+	got := filterRules([]string{"required", "ext:png", "sizeMax:10", "mimeIn:image/*"}, streamRules)
+	want := []string{"ext:png", "mimeIn:image/*"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterRules() = %v, want %v", got, want)
+	}
+}
+
+func Test_maxFileSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []string
+		want      int64
+		wantPanic bool
+	}{
+		{name: "test maxFileSize without size rules", rules: []string{"ext:png"}, want: -1},
+		{name: "test maxFileSize with sizeMax", rules: []string{"sizeMax:10"}, want: 10},
+		{name: "test maxFileSize with size", rules: []string{"size:7"}, want: 7},
+		{name: "test maxFileSize with sizeBetween", rules: []string{"sizeBetween:2,20", "sizeMax:30"}, want: 20},
+		{name: "test maxFileSize with invalid size", rules: []string{"sizeMax:x"}, wantPanic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("maxFileSize() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := maxFileSize(tt.rules); got != tt.want {
+				t.Errorf("maxFileSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateMultipart(t *testing.T) {
+	errHandler := errors.New("storage is down")
+	tests := []struct {
+		name        string
+		req         *http.Request
+		rules       Rules
+		opts        []Option
+		handlerErr  error
+		want        Errors
+		wantErr     error
+		wantHandled map[string]string
+		wantPanic   bool
+	}{
+		{
+			name: "test ValidateMultipart",
+			req: streamRequest(
+				testPart{name: "title", content: "passport"},
+				testPart{name: "age", content: "30"},
+				testPart{name: "docs[]", fileName: "a.pdf", contentType: "application/pdf", content: "pdf 1"},
+				testPart{name: "docs[]", fileName: "b.pdf", contentType: "application/pdf", content: "pdf 2"},
+			),
+			rules: Rules{
+				"title":  {"required"},
+				"age":    {"required", "int", "min:18"},
+				"docs":   {"required", "maxLen:2"},
+				"docs.*": {"extIn:pdf", "mime:application/pdf", "sizeMax:10"},
+			},
+			want:        Errors{},
+			wantHandled: map[string]string{"a.pdf": "pdf 1", "b.pdf": "pdf 2"},
+		},
+		{
+			name: "test ValidateMultipart with invalid values",
+			req: streamRequest(
+				testPart{name: "age", content: "x"},
+				testPart{name: "avatar", fileName: "a.png", contentType: "image/png", content: "png"},
+			),
+			rules:       Rules{"title": {"required"}, "age": {"int"}, "avatar": {"mimeIn:image/*"}},
+			want:        Errors{"title": "title is required", "age": "age must be an integer"},
+			wantHandled: map[string]string{"a.png": "png"},
+		},
+		{
+			name: "test ValidateMultipart with not allowed extension",
+			req: streamRequest(
+				testPart{name: "avatar", fileName: "a.exe", contentType: "image/png", content: "exe"},
+				testPart{name: "other", fileName: "b.png", contentType: "image/png", content: "png"},
+			),
+			rules:       Rules{"avatar": {"extIn:png,jpg"}, "other": {"ext:png"}},
+			want:        Errors{"avatar": "avatar's extension must be one of png,jpg"},
+			wantHandled: map[string]string{},
+		},
+		{
+			name:        "test ValidateMultipart with not allowed media type",
+			req:         streamRequest(testPart{name: "avatar", fileName: "a.png", contentType: "text/html", content: "<p>"}),
+			rules:       Rules{"avatar": {"mimeIn:image/png,image/jpeg"}},
+			want:        Errors{"avatar": "avatar's media type must be one of image/png,image/jpeg"},
+			wantHandled: map[string]string{},
+		},
+		{
+			name:        "test ValidateMultipart with too large file",
+			req:         streamRequest(testPart{name: "avatar", fileName: "a.png", contentType: "image/png", content: strings.Repeat("x", 100)}),
+			rules:       Rules{"avatar": {"sizeMax:10"}},
+			want:        Errors{"avatar": "avatar's size must be lower than or equal 10"},
+			wantHandled: map[string]string{},
+		},
+		{
+			name:        "test ValidateMultipart with too small file",
+			req:         streamRequest(testPart{name: "avatar", fileName: "a.png", contentType: "image/png", content: "png"}),
+			rules:       Rules{"avatar": {"sizeMin:10"}},
+			want:        Errors{"avatar": "avatar's size must be greater than or equal 10"},
+			wantHandled: map[string]string{"a.png": "png"},
+		},
+		{
+			name:        "test ValidateMultipart with handler error",
+			req:         streamRequest(testPart{name: "avatar", fileName: "a.png", contentType: "image/png", content: "png"}),
+			rules:       Rules{"avatar": {"required"}},
+			handlerErr:  errHandler,
+			want:        nil,
+			wantErr:     errHandler,
+			wantHandled: map[string]string{"a.png": "png"},
+		},
+		{
+			name: "test ValidateMultipart with too many files",
+			req: streamRequest(
+				testPart{name: "docs[]", fileName: "a.pdf", contentType: "application/pdf", content: "pdf 1"},
+				testPart{name: "docs[]", fileName: "b.pdf", contentType: "application/pdf", content: "pdf 2"},
+			),
+			rules:     Rules{"docs": {"required"}},
+			opts:      []Option{WithLimits(Limits{MaxFiles: 1})},
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateMultipart with too large body",
+			req:       streamRequest(testPart{name: "avatar", fileName: "a.png", contentType: "image/png", content: "png"}),
+			rules:     Rules{"avatar": {"required"}},
+			opts:      []Option{WithLimits(Limits{MaxBodyBytes: 10})},
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateMultipart with too deep field",
+			req:       streamRequest(testPart{name: "a" + strings.Repeat("[x]", 5000), content: "1"}),
+			rules:     Rules{"a": {"required"}},
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateMultipart with non multipart request",
+			req:       jsonRequest(),
+			rules:     Rules{"lang": {"required"}},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				e := recover()
+				if (e != nil) != tt.wantPanic {
+					t.Errorf("ValidateMultipart() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+				if _, ok := e.(*RequestError); e != nil && !ok {
+					t.Errorf("ValidateMultipart() panic = %T, want *RequestError", e)
+				}
+			}()
+			handled := make(map[string]string)
+			got, err := ValidateMultipart(tt.req, tt.rules, func(p *Part) error {
+				b, err := io.ReadAll(p)
+				if err != nil {
+					return err
+				}
+				handled[p.FileHeader.Filename] = string(b)
+				return tt.handlerErr
+			}, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateMultipart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateMultipart() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(handled, tt.wantHandled) {
+				t.Errorf("ValidateMultipart() handled = %v, want %v", handled, tt.wantHandled)
+			}
+		})
+	}
+}
+
+func Test_ValidateMultipart_storesValues(t *testing.T) {
+	r := streamRequest(testPart{name: "title", content: "passport"})
+	if _, err := ValidateMultipart(r, Rules{"title": {"required"}}, func(p *Part) error { return nil }); err != nil {
+		t.Fatalf("ValidateMultipart() error = %v", err)
+	}
+	if got := r.PostFormValue("title"); got != "passport" {
+		t.Errorf("PostFormValue() = %v, want passport", got)
+	}
+	if got := r.MultipartForm.Value["title"]; !reflect.DeepEqual(got, []string{"passport"}) {
+		t.Errorf("MultipartForm.Value = %v, want [passport]", got)
+	}
+}
@@ -0,0 +1,137 @@
+package valdn
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Option configures how a request or a document is validated.
+type Option func(*config)
+
+type config struct {
+	errorResponder ErrorResponder
+	routes         Routes
+	contentTypes   []string
+	lossless       bool
+	mergePolicy    MergePolicy
+	strictSources  bool
+	limits         Limits
+	strictJSON     bool
+	knownFields    bool
+	pathFormat     PathFormatter
+	protoRules     protoreflect.ExtensionType
+	discriminators []discriminator
+	namespaces     bool
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		errorResponder: defaultErrorResponder,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithErrorResponder sets the function Middleware uses to write failed validations.
+func WithErrorResponder(fn ErrorResponder) Option {
+	return func(c *config) {
+		c.errorResponder = fn
+	}
+}
+
+// WithRoutes sets rules per route, so one Middleware can serve a whole mux.
+// Routes are keyed by net/http.ServeMux patterns, e.g. "POST /users/{id}".
+func WithRoutes(routes Routes) Option {
+	return func(c *config) {
+		c.routes = routes
+	}
+}
+
+// WithContentTypes restricts the content types a request body may have, e.g. "application/json".
+// An item that starts with + (e.g. "+json") allows every media type with that suffix.
+// Requests of other content types make ValidateRequest panic with RequestError wrapping ErrUnsupportedMediaType.
+func WithContentTypes(mediaTypes ...string) Option {
+	return func(c *config) {
+		c.contentTypes = append([]string{}, mediaTypes...)
+	}
+}
+
+// WithNamespaces validates request values by where they come from using namespaced rule names: header.X-Request-ID,
+// cookie.session, path.id (r.PathValue("id")), query.page and body.email.
+// Without it, these names are names of nested body and url values, e.g. path.id is the id of a path object.
+// Body and url values named header, cookie, path, query or body are replaced by the namespace values, and reported
+// in Errors, if rules of that namespace are used.
+func WithNamespaces() Option {
+	return func(c *config) {
+		c.namespaces = true
+	}
+}
+
+// WithLossless keeps non JSON request values as strings, instead of converting them by the types their rules declare.
+func WithLossless() Option {
+	return func(c *config) {
+		c.lossless = true
+	}
+}
+
+// WithMergePolicy sets what happens to url params that have the same name as body values, MergeAppend by default.
+func WithMergePolicy(policy MergePolicy) Option {
+	return func(c *config) {
+		c.mergePolicy = policy
+	}
+}
+
+// WithStrictSources rejects request values that may be polluted: names sent in both body and url params,
+// and names sent many times while their rules expect one value (no kind:slice, kind:array or nested rules),
+// nested values, headers and cookies included.
+func WithStrictSources() Option {
+	return func(c *config) {
+		c.strictSources = true
+	}
+}
+
+// WithLimits bounds the size and the shape of validated requests and JSON strings, see Limits.
+func WithLimits(limits Limits) Option {
+	return func(c *config) {
+		c.limits = limits
+	}
+}
+
+// WithStrictJSON rejects JSON documents that encoding/json silently accepts: objects with duplicate keys,
+// data after the top-level value, invalid UTF-8 and lone surrogates. Rejections are reported by JSONError.
+func WithStrictJSON() Option {
+	return func(c *config) {
+		c.strictJSON = true
+	}
+}
+
+// WithDisallowUnknownFields rejects JSON documents that have object keys with no rules, by JSONError wrapping
+// ErrUnknownField. Fields of the schemas of schema rules are known under the fields that have the schema rules.
+// Keys of objects that have no rules for their children are not checked.
+func WithDisallowUnknownFields() Option {
+	return func(c *config) {
+		c.knownFields = true
+	}
+}
+
+// WithPathFormat sets how errors are keyed by the paths of the values they're about, DottedPath by default.
+// Use BracketPath for items[0].name, JSONPointerPath for /items/0/name, or any other PathFormatter.
+// Rules are keyed in dotted notation whatever the path format is.
+func WithPathFormat(f PathFormatter) Option {
+	return func(c *config) {
+		c.pathFormat = f
+	}
+}
+
+// checkJSON checks data by strict JSON decoding if it's enabled by WithStrictJSON or WithDisallowUnknownFields.
+func (c *config) checkJSON(data []byte, rules Rules) error {
+	if !c.strictJSON && !c.knownFields {
+		return nil
+	}
+	var known func(p Path) bool
+	if c.knownFields {
+		known = knownFields(discriminatorRules(rules, c.discriminators))
+	}
+	return checkStrictJSON(data, known, c.limits.MaxDepth)
+}
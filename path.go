@@ -0,0 +1,185 @@
+package valdn
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Path is the location of a value in a collection, map keys and struct field names are strings,
+// and slice and array indexes are ints.
+type Path []interface{}
+
+// PathFormatter formats the path of a value into the name its errors are keyed by, see WithPathFormat.
+// The root value has no path, its errors are keyed by RootName.
+type PathFormatter func(p Path) string
+
+// String returns p in dotted notation, e.g. items.0.name, the notation rules are keyed by.
+func (p Path) String() string {
+	return strings.Join(p.segments(), ".")
+}
+
+// segments returns the segments of p as strings.
+func (p Path) segments() []string {
+	segments := make([]string, len(p))
+	for i, s := range p {
+		segments[i] = segmentString(s)
+	}
+	return segments
+}
+
+// segmentString returns segment s as it's written in dotted names.
+func segmentString(s interface{}) string {
+	switch s := s.(type) {
+	case string:
+		return s
+	case int:
+		return strconv.Itoa(s)
+	}
+	return toString(s)
+}
+
+// prefix returns the prefix of the dotted names of the values nested in p, e.g. items.0. of items.0,
+// or an empty string if p is the root.
+func (p Path) prefix() string {
+	if len(p) == 0 {
+		return ""
+	}
+	return p.String() + "."
+}
+
+// join returns a copy of p with segment appended, so paths of siblings don't share their backing array.
+func (p Path) join(segment interface{}) Path {
+	joined := make(Path, len(p), len(p)+1)
+	copy(joined, p)
+	return append(joined, segment)
+}
+
+// child returns p with segment appended like join, but it reuses the backing array of p if it has room, so paths
+// along one branch of a deeply nested value aren't copied at every level. Paths of siblings may share their backing
+// array, so a path returned by child is valid only while its value is walked, use join to keep it.
+func (p Path) child(segment interface{}) Path {
+	return append(p, segment)
+}
+
+// childName returns the dotted name of p joined with segment, name is the dotted name of p, so dotted names of
+// nested values are joined to the names of their parents instead of being built from their paths.
+func (p Path) childName(name string, segment interface{}) string {
+	if len(p) == 0 {
+		return segmentString(segment)
+	}
+	return name + "." + segmentString(segment)
+}
+
+// splitName splits dotted name into a path, segments of digits are considered indexes.
+func splitName(name string) Path {
+	if name == "" {
+		return nil
+	}
+	segments := strings.Split(name, ".")
+	p := make(Path, len(segments))
+	for i, s := range segments {
+		if idx, err := strconv.Atoi(s); err == nil && idx >= 0 && strconv.Itoa(idx) == s {
+			p[i] = idx
+			continue
+		}
+		p[i] = s
+	}
+	return p
+}
+
+// valuePath returns the path of dotted name in val: segments of map keys and struct fields are strings, and segments
+// of slice and array items are indexes, e.g. m.0 of {"m": {"0": 1}} is the key "0" of m.
+// Segments of values val doesn't have are considered indexes if they're digits, like splitName.
+func valuePath(val interface{}, name string) Path {
+	p := splitName(name)
+	cur := reflect.ValueOf(val)
+	for i, s := range p {
+		for cur.IsValid() && (cur.Kind() == reflect.Interface || cur.Kind() == reflect.Ptr) {
+			cur = cur.Elem()
+		}
+		if !cur.IsValid() {
+			break
+		}
+		switch cur.Kind() {
+		case reflect.Map:
+			p[i] = toString(s)
+			if cur.Type().Key().Kind() != reflect.String {
+				return p
+			}
+			cur = cur.MapIndex(reflect.ValueOf(p[i]).Convert(cur.Type().Key()))
+		case reflect.Struct:
+			p[i] = toString(s)
+			cur = cur.FieldByName(toString(s))
+		case reflect.Slice, reflect.Array:
+			idx, ok := s.(int)
+			if !ok || idx >= cur.Len() {
+				return p
+			}
+			cur = cur.Index(idx)
+		default:
+			return p
+		}
+	}
+	return p
+}
+
+// formatName formats dotted name of a value of root by f, or in dotted notation if f is nil, see valuePath.
+// RootName is kept as it is.
+func formatName(root interface{}, name string, f PathFormatter) string {
+	if name == RootName || f == nil {
+		return name
+	}
+	return f(valuePath(root, name))
+}
+
+// DottedPath formats p in dotted notation, e.g. items.0.name, it's the default PathFormatter.
+func DottedPath(p Path) string {
+	return p.String()
+}
+
+// BracketPath formats p in bracket notation, e.g. items[0].name.
+// Keys that are not identifiers are quoted, e.g. meta["a.b"] and meta["0"].
+func BracketPath(p Path) string {
+	var b strings.Builder
+	for i, s := range p {
+		if idx, ok := s.(int); ok {
+			b.WriteString("[" + strconv.Itoa(idx) + "]")
+			continue
+		}
+		key := toString(s)
+		switch {
+		case !isIdentifier(key):
+			b.WriteString("[" + strconv.Quote(key) + "]")
+		case i > 0:
+			b.WriteString("." + key)
+		default:
+			b.WriteString(key)
+		}
+	}
+	return b.String()
+}
+
+// JSONPointerPath formats p as a JSON Pointer (RFC 6901), e.g. /items/0/name, ~ and / in keys are escaped.
+func JSONPointerPath(p Path) string {
+	var b strings.Builder
+	for _, s := range p {
+		b.WriteByte('/')
+		b.WriteString(pointerEscaper.Replace(toString(s)))
+	}
+	return b.String()
+}
+
+// isIdentifier reports weather s is a letter or underscore followed by letters, digits or underscores.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		isLetter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !isLetter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,197 @@
+package valdn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Path_String(t *testing.T) {
+	if got, want := (Path{"items", 0, "name"}).String(), "items.0.name"; got != want {
+		t.Errorf("Path.String() = %v, want %v", got, want)
+	}
+}
+
+func Test_Path_join(t *testing.T) {
+	parent := make(Path, 1, 4)
+	parent[0] = "items"
+	a := parent.join(0)
+	b := parent.join(1)
+	if !reflect.DeepEqual(a, Path{"items", 0}) || !reflect.DeepEqual(b, Path{"items", 1}) {
+		t.Errorf("Path.join() = %v and %v, want [items 0] and [items 1]", a, b)
+	}
+}
+
+func Test_Path_child(t *testing.T) {
+	parent := Path{"items"}
+	item := parent.child(0)
+	name := item.child("name")
+	if !reflect.DeepEqual(parent, Path{"items"}) || !reflect.DeepEqual(name, Path{"items", 0, "name"}) {
+		t.Errorf("Path.child() = %v of %v, want [items 0 name] of [items]", name, parent)
+	}
+}
+
+func Test_Path_childName(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Path
+		parName string
+		segment interface{}
+		want    string
+	}{
+		{name: "test child name of root", p: nil, parName: "", segment: "items", want: "items"},
+		{name: "test child name of index", p: Path{"items"}, parName: "items", segment: 10, want: "items.10"},
+		{name: "test child name of key with dots", p: Path{"m"}, parName: "m", segment: "a.b", want: "m.a.b"},
+		{name: "test child name of empty key", p: Path{""}, parName: "", segment: "a", want: ".a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.p.childName(tt.parName, tt.segment)
+			if got != tt.want {
+				t.Errorf("Path.childName() = %v, want %v", got, tt.want)
+			}
+			if joined := tt.p.join(tt.segment).String(); got != joined {
+				t.Errorf("Path.childName() = %v, Path.String() = %v", got, joined)
+			}
+		})
+	}
+}
+
+func Test_splitName(t *testing.T) {
+	tests := []struct {
+		name string
+		want Path
+	}{
+		{name: "", want: nil},
+		{name: "name", want: Path{"name"}},
+		{name: "items.0.name", want: Path{"items", 0, "name"}},
+		{name: "items.01.-1", want: Path{"items", "01", "-1"}},
+	}
+	for _, tt := range tests {
+		t.Run("test splitName with "+tt.name, func(t *testing.T) {
+			if got := splitName(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitName() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_valuePath(t *testing.T) {
+	type user struct {
+		Tags []string
+	}
+	val := map[string]interface{}{"m": map[string]interface{}{"0": 1}, "items": []interface{}{map[string]interface{}{}},
+		"user": &user{Tags: []string{"a"}}}
+	tests := []struct {
+		name string
+		want Path
+	}{
+		{name: "m.0", want: Path{"m", "0"}},
+		{name: "items.0.1", want: Path{"items", 0, "1"}},
+		{name: "items.1.0", want: Path{"items", 1, 0}},
+		{name: "user.Tags.0", want: Path{"user", "Tags", 0}},
+		{name: "missing.0", want: Path{"missing", 0}},
+	}
+	for _, tt := range tests {
+		t.Run("test valuePath with "+tt.name, func(t *testing.T) {
+			if got := valuePath(val, tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valuePath() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatName(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     interface{}
+		formName string
+		f        PathFormatter
+		want     string
+	}{
+		{name: "test formatName without formatter", formName: "items.0.name", f: nil, want: "items.0.name"},
+		{name: "test formatName with formatter", formName: "items.0.name", f: JSONPointerPath, want: "/items/0/name"},
+		{name: "test formatName with root", formName: RootName, f: JSONPointerPath, want: RootName},
+		{name: "test formatName with key of digits", root: map[string]interface{}{"m": map[string]interface{}{"0": "a"}},
+			formName: "m.0", f: BracketPath, want: `m["0"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatName(tt.root, tt.formName, tt.f); got != tt.want {
+				t.Errorf("formatName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_PathFormatters(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        Path
+		dotted      string
+		bracket     string
+		jsonPointer string
+	}{
+		{
+			name:        "test path formatters with field",
+			path:        Path{"name"},
+			dotted:      "name",
+			bracket:     "name",
+			jsonPointer: "/name",
+		},
+		{
+			name:        "test path formatters with nested path",
+			path:        Path{"items", 0, "name"},
+			dotted:      "items.0.name",
+			bracket:     "items[0].name",
+			jsonPointer: "/items/0/name",
+		},
+		{
+			name:        "test path formatters with index root",
+			path:        Path{0, "name"},
+			dotted:      "0.name",
+			bracket:     "[0].name",
+			jsonPointer: "/0/name",
+		},
+		{
+			name:        "test path formatters with special keys",
+			path:        Path{"meta", "a.b", "0", "c/d~e", ""},
+			dotted:      "meta.a.b.0.c/d~e.",
+			bracket:     `meta["a.b"]["0"]["c/d~e"][""]`,
+			jsonPointer: "/meta/a.b/0/c~1d~0e/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DottedPath(tt.path); got != tt.dotted {
+				t.Errorf("DottedPath() = %v, want %v", got, tt.dotted)
+			}
+			if got := BracketPath(tt.path); got != tt.bracket {
+				t.Errorf("BracketPath() = %v, want %v", got, tt.bracket)
+			}
+			if got := JSONPointerPath(tt.path); got != tt.jsonPointer {
+				t.Errorf("JSONPointerPath() = %v, want %v", got, tt.jsonPointer)
+			}
+		})
+	}
+}
+
+func Test_isIdentifier(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "name", want: true},
+		{s: "_id2", want: true},
+		{s: "2id", want: false},
+		{s: "a.b", want: false},
+		{s: "", want: false},
+		{s: "é", want: false},
+	}
+	for _, tt := range tests {
+		t.Run("test isIdentifier with "+tt.s, func(t *testing.T) {
+			if got := isIdentifier(tt.s); got != tt.want {
+				t.Errorf("isIdentifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
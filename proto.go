@@ -0,0 +1,161 @@
+package valdn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fieldOptionsName is the full name of the message protobuf field options are of.
+const fieldOptionsName = "google.protobuf.FieldOptions"
+
+// WithProtoRules reads rules of protobuf fields from the custom field option xt, as an alternative to struct tags.
+// xt must extend google.protobuf.FieldOptions by a string of rules separated by TagSeparator (e.g. "required|min:1")
+// or by repeated strings of rules, rules of fields that are given to ValidateProto are not replaced.
+// It panics if xt doesn't extend google.protobuf.FieldOptions.
+func WithProtoRules(xt protoreflect.ExtensionType) Option {
+	if name := xt.TypeDescriptor().ContainingMessage().FullName(); name != fieldOptionsName {
+		panic(fmt.Errorf("WithProtoRules: xt must extend %v got %v", fieldOptionsName, name))
+	}
+	return func(c *config) {
+		c.protoRules = xt
+	}
+}
+
+// protoWalker converts protobuf messages to maps, and adds the rules of their field options to rules.
+type protoWalker struct {
+	rules Rules
+	xt    protoreflect.ExtensionType
+}
+
+// ValidateProto validates protobuf message msg by rules and returns Errors.
+// Messages are traversed by protoreflect and validated like maps keyed by their proto field names (e.g. shipping_address),
+// nested messages are maps, repeated fields are slices and map fields are maps keyed by their keys as strings.
+// Fields that track presence (optional fields, message fields and fields of oneofs) are absent if they're not set,
+// other fields have their values even if they're zero. Oneofs are named by their names and have the name of the field
+// that is set, so required and in rules work on them.
+// Enums are the names of their values, google.protobuf.Timestamp and google.protobuf.Duration are time.Time and
+// time.Duration, and wrappers (e.g. google.protobuf.StringValue) are their values.
+// Use WithProtoRules to read rules from a custom field option, and WithPathFormat to format the paths of errors.
+// If an error is found it will not check the rest of the field's rules and continue to the next field.
+// It panics if one of the rules is not registered.
+func ValidateProto(msg proto.Message, rules Rules, opts ...Option) Errors {
+	c := newConfig(opts)
+	w := &protoWalker{rules: rules, xt: c.protoRules}
+	if c.protoRules != nil {
+		w.rules = copyRules(rules)
+	}
+	val := w.message(msg.ProtoReflect(), "")
+	return ValidateCollection(val, w.rules, WithPathFormat(c.pathFormat))
+}
+
+// message converts m named name to a map of its fields.
+func (w *protoWalker) message(m protoreflect.Message, name string) map[string]interface{} {
+	val := make(map[string]interface{})
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		fName := joinName(name, string(fd.Name()))
+		w.addOptionRules(fd, fName)
+		if fd.HasPresence() && !m.Has(fd) {
+			continue
+		}
+		val[string(fd.Name())] = w.field(fd, m.Get(fd), fName)
+	}
+
+	oneofs := m.Descriptor().Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		od := oneofs.Get(i)
+		// synthetic oneofs are proto3 optional fields
+		if od.IsSynthetic() {
+			continue
+		}
+		if fd := m.WhichOneof(od); fd != nil {
+			val[string(od.Name())] = string(fd.Name())
+		}
+	}
+	return val
+}
+
+// field converts v of field fd named name, lists are converted to slices and maps are converted to maps.
+func (w *protoWalker) field(fd protoreflect.FieldDescriptor, v protoreflect.Value, name string) interface{} {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		s := make([]interface{}, list.Len())
+		for i := range s {
+			s[i] = w.value(fd, list.Get(i), joinName(name, strconv.Itoa(i)))
+		}
+		return s
+	case fd.IsMap():
+		m := make(map[string]interface{}, v.Map().Len())
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			key := toString(k.Interface())
+			m[key] = w.value(fd.MapValue(), mv, joinName(name, key))
+			return true
+		})
+		return m
+	}
+	return w.value(fd, v, name)
+}
+
+// value converts single value v of field fd named name.
+func (w *protoWalker) value(fd protoreflect.FieldDescriptor, v protoreflect.Value, name string) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if val, ok := wellKnownValue(v.Message()); ok {
+			return val
+		}
+		return w.message(v.Message(), name)
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	}
+	return v.Interface()
+}
+
+// addOptionRules adds the rules of the field option of fd to the rules of name, if it has no rules.
+func (w *protoWalker) addOptionRules(fd protoreflect.FieldDescriptor, name string) {
+	if w.xt == nil {
+		return
+	}
+	if _, ok := w.rules[name]; ok {
+		return
+	}
+	opts, ok := fd.Options().(proto.Message)
+	if !ok || !proto.HasExtension(opts, w.xt) {
+		return
+	}
+	switch r := proto.GetExtension(opts, w.xt).(type) {
+	case string:
+		w.rules[name] = strings.Split(r, TagSeparator)
+	case []string:
+		w.rules[name] = append([]string{}, r...)
+	}
+}
+
+// wellKnownValue returns the Go value of well known message m (timestamps, durations and wrappers),
+// and reports weather m is one of them.
+func wellKnownValue(m protoreflect.Message) (interface{}, bool) {
+	md := m.Descriptor()
+	get := func(name protoreflect.Name) protoreflect.Value {
+		return m.Get(md.Fields().ByName(name))
+	}
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return time.Unix(get("seconds").Int(), get("nanos").Int()).UTC(), true
+	case "google.protobuf.Duration":
+		return time.Duration(get("seconds").Int())*time.Second + time.Duration(get("nanos").Int()), true
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return get("value").Interface(), true
+	}
+	return nil, false
+}
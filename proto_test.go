@@ -0,0 +1,324 @@
+package valdn
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newProtoTestTypes builds the message types of ValidateProto tests:
+//
+//	extend google.protobuf.FieldOptions { string rules = 50001; }
+//	enum Status { STATUS_UNSPECIFIED = 0; STATUS_ACTIVE = 1; }
+//	message Item { string sku = 1 [(rules) = "required|minLen:2"]; int32 qty = 2; }
+//	message Order {
+//	  string id = 1;
+//	  optional int32 priority = 2;
+//	  Item item = 3;
+//	  repeated Item items = 4;
+//	  map<string, int32> stock = 5;
+//	  oneof contact { string email = 6; string phone = 7; }
+//	  Status status = 8;
+//	  google.protobuf.Timestamp created_at = 9;
+//	  google.protobuf.Duration ttl = 10;
+//	  google.protobuf.StringValue note = 11;
+//	  repeated string tags = 12;
+//	}
+func newProtoTestTypes(t *testing.T) (protoreflect.MessageType, protoreflect.ExtensionType) {
+	t.Helper()
+	files := new(protoregistry.Files)
+	for _, fd := range []protoreflect.FileDescriptor{
+		descriptorpb.File_google_protobuf_descriptor_proto,
+		timestamppb.File_google_protobuf_timestamp_proto,
+		durationpb.File_google_protobuf_duration_proto,
+		wrapperspb.File_google_protobuf_wrappers_proto,
+	} {
+		if err := files.RegisterFile(fd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newFile := func(fdp *descriptorpb.FileDescriptorProto) protoreflect.FileDescriptor {
+		fd, err := protodesc.NewFile(fdp, files)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = files.RegisterFile(fd); err != nil {
+			t.Fatal(err)
+		}
+		return fd
+	}
+
+	optionsFile := newFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("valdn/options.proto"),
+		Package:    proto.String("valdn"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("rules"),
+			Number:   proto.Int32(50001),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Extendee: proto.String(".google.protobuf.FieldOptions"),
+		}},
+	})
+	xt := dynamicpb.NewExtensionType(optionsFile.Extensions().Get(0))
+	skuOptions := new(descriptorpb.FieldOptions)
+	proto.SetExtension(skuOptions, xt, "required|minLen:2")
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return f
+	}
+	inOneof := func(f *descriptorpb.FieldDescriptorProto, i int32) *descriptorpb.FieldDescriptorProto {
+		f.OneofIndex = proto.Int32(i)
+		return f
+	}
+	const (
+		tString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		tInt32   = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		tMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+		tEnum    = descriptorpb.FieldDescriptorProto_TYPE_ENUM
+	)
+	sku := field("sku", 1, tString, "")
+	sku.Options = skuOptions
+	priority := inOneof(field("priority", 2, tInt32, ""), 1)
+	priority.Proto3Optional = proto.Bool(true)
+
+	orderFile := newFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("valdn/order.proto"),
+		Package: proto.String("valdn"),
+		Syntax:  proto.String("proto3"),
+		Dependency: []string{
+			"valdn/options.proto",
+			"google/protobuf/timestamp.proto",
+			"google/protobuf/duration.proto",
+			"google/protobuf/wrappers.proto",
+		},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("STATUS_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("STATUS_ACTIVE"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{sku, field("qty", 2, tInt32, "")},
+			},
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, tString, ""),
+					priority,
+					field("item", 3, tMessage, ".valdn.Item"),
+					repeated(field("items", 4, tMessage, ".valdn.Item")),
+					repeated(field("stock", 5, tMessage, ".valdn.Order.StockEntry")),
+					inOneof(field("email", 6, tString, ""), 0),
+					inOneof(field("phone", 7, tString, ""), 0),
+					field("status", 8, tEnum, ".valdn.Status"),
+					field("created_at", 9, tMessage, ".google.protobuf.Timestamp"),
+					field("ttl", 10, tMessage, ".google.protobuf.Duration"),
+					field("note", 11, tMessage, ".google.protobuf.StringValue"),
+					repeated(field("tags", 12, tString, "")),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name:    proto.String("StockEntry"),
+					Field:   []*descriptorpb.FieldDescriptorProto{field("key", 1, tString, ""), field("value", 2, tInt32, "")},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: proto.String("contact")},
+					{Name: proto.String("_priority")},
+				},
+			},
+		},
+	})
+	return dynamicpb.NewMessageType(orderFile.Messages().ByName("Order")), xt
+}
+
+func Test_ValidateProto(t *testing.T) {
+	orderType, xt := newProtoTestTypes(t)
+	md := orderType.Descriptor()
+	itemType := dynamicpb.NewMessageType(md.Fields().ByName("item").Message())
+	newItem := func(sku string, qty int32) protoreflect.Value {
+		item := itemType.New()
+		item.Set(item.Descriptor().Fields().ByName("sku"), protoreflect.ValueOfString(sku))
+		item.Set(item.Descriptor().Fields().ByName("qty"), protoreflect.ValueOfInt32(qty))
+		return protoreflect.ValueOfMessage(item)
+	}
+	createdAt := time.Date(2021, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	order := orderType.New()
+	set := func(name string, v protoreflect.Value) {
+		order.Set(md.Fields().ByName(protoreflect.Name(name)), v)
+	}
+	set("id", protoreflect.ValueOfString("o-1"))
+	set("item", newItem("a", 0))
+	items := order.Mutable(md.Fields().ByName("items")).List()
+	items.Append(newItem("ab", 2))
+	items.Append(newItem("", 1))
+	stock := order.Mutable(md.Fields().ByName("stock")).Map()
+	stock.Set(protoreflect.ValueOfString("ab").MapKey(), protoreflect.ValueOfInt32(-1))
+	set("phone", protoreflect.ValueOfString("+201000000000"))
+	set("status", protoreflect.ValueOfEnum(1))
+	set("created_at", protoreflect.ValueOfMessage(timestamppb.New(createdAt).ProtoReflect()))
+	set("ttl", protoreflect.ValueOfMessage(durationpb.New(90*time.Second).ProtoReflect()))
+	set("note", protoreflect.ValueOfMessage(wrapperspb.String("fragile").ProtoReflect()))
+
+	tests := []struct {
+		name      string
+		msg       proto.Message
+		rules     Rules
+		opts      []Option
+		want      Errors
+		wantPanic bool
+	}{
+		{
+			name: "test ValidateProto",
+			msg:  order.Interface(),
+			rules: Rules{
+				"$":           {"required", "kind:map"},
+				"id":          {"required", "minLen:3"},
+				"priority":    {"int"},
+				"contact":     {"required", "in:email,phone"},
+				"phone":       {"required", "phoneNumber"},
+				"status":      {"in:STATUS_ACTIVE"},
+				"created_at":  {"required", "time"},
+				"ttl":         {"kind:int64", "max:60000000000"},
+				"note":        {"required", "kind:string"},
+				"items":       {"required", "kind:slice", "maxLen:5"},
+				"items.1.sku": {"required"},
+				"stock.*":     {"kind:int32", "min:0"},
+				"tags":        {"maxLen:1"},
+			},
+			want: Errors{
+				"ttl":         GetErrMsg("max", "60000000000", "ttl", 90*time.Second),
+				"items.1.sku": GetErrMsg("required", "", "items.1.sku", ""),
+				"stock.ab":    GetErrMsg("min", "0", "stock.ab", int32(-1)),
+			},
+		},
+		{
+			name:  "test ValidateProto with unset fields",
+			msg:   orderType.New().Interface(),
+			rules: Rules{"id": {"required"}, "priority": {"required"}, "contact": {"required"}, "item": {"required"}, "email": {"required"}},
+			want: Errors{
+				"id":       GetErrMsg("required", "", "id", ""),
+				"priority": GetErrMsg("required", "", "priority", nil),
+				"contact":  GetErrMsg("required", "", "contact", nil),
+				"item":     GetErrMsg("required", "", "item", nil),
+				"email":    GetErrMsg("required", "", "email", nil),
+			},
+		},
+		{
+			name:  "test ValidateProto with option rules",
+			msg:   order.Interface(),
+			rules: Rules{"items.0.sku": {"required"}},
+			opts:  []Option{WithProtoRules(xt)},
+			want: Errors{
+				"item.sku":    GetErrMsg("minLen", "2", "item.sku", "a"),
+				"items.1.sku": GetErrMsg("required", "", "items.1.sku", ""),
+			},
+		},
+		{
+			name:  "test ValidateProto with path format",
+			msg:   order.Interface(),
+			rules: Rules{"stock.*": {"min:0"}},
+			opts:  []Option{WithPathFormat(JSONPointerPath)},
+			want:  Errors{"/stock/ab": GetErrMsg("min", "0", "/stock/ab", int32(-1))},
+		},
+		{
+			name:  "test ValidateProto with generated message",
+			msg:   timestamppb.New(createdAt),
+			rules: Rules{"seconds": {"required", "max:1"}, "nanos": {"min:0"}},
+			want:  Errors{"seconds": GetErrMsg("max", "1", "seconds", createdAt.Unix())},
+		},
+		{
+			name:      "test ValidateProto with unknown rule",
+			msg:       order.Interface(),
+			rules:     Rules{"id": {"unknownRule"}},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("ValidateProto() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := ValidateProto(tt.msg, tt.rules, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateProto() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_wellKnownValue(t *testing.T) {
+	createdAt := time.Date(2021, 1, 2, 3, 4, 5, 6, time.UTC)
+	tests := []struct {
+		name   string
+		msg    proto.Message
+		want   interface{}
+		wantOk bool
+	}{
+		{name: "test wellKnownValue with timestamp", msg: timestamppb.New(createdAt), want: createdAt, wantOk: true},
+		{name: "test wellKnownValue with duration", msg: durationpb.New(-1500 * time.Millisecond), want: -1500 * time.Millisecond, wantOk: true},
+		{name: "test wellKnownValue with wrapper", msg: wrapperspb.Int32(7), want: int32(7), wantOk: true},
+		{name: "test wellKnownValue with other message", msg: &descriptorpb.FieldOptions{}, want: nil, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := wellKnownValue(tt.msg.ProtoReflect())
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOk {
+				t.Errorf("wellKnownValue() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_WithProtoRules(t *testing.T) {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("valdn/message_options.proto"),
+		Package:    proto.String("valdn"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("message_rules"),
+			Number:   proto.Int32(50002),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Extendee: proto.String(".google.protobuf.MessageOptions"),
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("WithProtoRules() didn't panic with extension of google.protobuf.MessageOptions")
+		}
+	}()
+	WithProtoRules(dynamicpb.NewExtensionType(fd.Extensions().Get(0)))
+}
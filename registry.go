@@ -0,0 +1,150 @@
+package valdn
+
+import (
+	"errors"
+)
+
+var registeredSchemas = make(map[string]Rules)
+
+// RegisterSchema registers rules of objects as schema name, so the objects can be validated by schema:name rule
+// instead of copying rules under every path they're at, e.g. RegisterSchema("address", Rules{"city": {"required"}})
+// then Rules{"billing": {"required", "schema:address"}, "shipping": {"schema:address"}}.
+// rules are keyed by the names of the fields of the objects, and may have schema rules of other schemas or of itself,
+// e.g. children.* of schema:node, they're applied to the objects that are validated only so they always terminate.
+// It panics if the schema is already registered, or if one of the rules is not registered.
+func RegisterSchema(name string, rules Rules) {
+	if _, ok := registeredSchemas[name]; ok {
+		panic("schema is already registered: " + name)
+	}
+	for k, r := range rules {
+		checkRulesExist(k, r)
+	}
+	registeredSchemas[name] = deepCopyRules(rules)
+}
+
+// schemaRule checks if val is a collection (struct, map, slice or array), the fields of val are validated by the rules
+// of schema ruleVal.
+// It panics if schema ruleVal is not registered.
+// It returns error if val is not a collection.
+func schemaRule(name string, val interface{}, ruleVal string) error {
+	if _, ok := registeredSchemas[ruleVal]; !ok {
+		panic("unknown schema: " + ruleVal)
+	}
+	if !IsCollection(val) {
+		return errors.New(GetErrMsg("schema", ruleVal, name, val))
+	}
+	return nil
+}
+
+// addSchemaRules adds the rules of the schemas of schema rules of rules to the fields of the object at p.
+// It panics if a schema is not registered.
+func (v *validation) addSchemaRules(p Path, rules []string) {
+	for _, r := range expandAliases(rules) {
+		rName, rVal := splitRuleNameAndRuleValue(r)
+		if rName != "schema" {
+			continue
+		}
+		schema, ok := registeredSchemas[rVal]
+		if !ok {
+			panic("unknown schema: " + rVal)
+		}
+		v.addRules(p, schema)
+	}
+}
+
+// addRules adds rules keyed by the names of the fields of the object at p to the rules of the fields,
+// fields that have no rules of their own have the rules of their wildcards before rules.
+func (v *validation) addRules(p Path, rules Rules) {
+	for k, r := range rules {
+		k = joinName(p.String(), k)
+		v.rules[k] = append(append([]string{}, v.getFieldRules(k)...), r...)
+	}
+}
+
+// schemaRules returns rules with the rules of the schemas of their schema rules keyed under the fields that have them,
+// like addSchemaRules adds them while validating, so the fields of the schemas are known by WithDisallowUnknownFields.
+// Schemas are expanded once in the fields of themselves (e.g. children.* of schema:node), so deeper fields are
+// free-form. Schemas that are not registered are skipped.
+func schemaRules(rules Rules) Rules {
+	expanded := copyRules(rules)
+	var expand func(name string, fRules []string, parents map[string]bool)
+	expand = func(name string, fRules []string, parents map[string]bool) {
+		for _, r := range expandAliases(fRules) {
+			rName, rVal := splitRuleNameAndRuleValue(r)
+			schema, ok := registeredSchemas[rVal]
+			if rName != "schema" || !ok || parents[rVal] {
+				continue
+			}
+			nested := map[string]bool{rVal: true}
+			for s := range parents {
+				nested[s] = true
+			}
+			for k, kRules := range schema {
+				k = joinName(name, k)
+				expanded[k] = append(append([]string{}, expanded[k]...), kRules...)
+				expand(k, kRules, nested)
+			}
+		}
+	}
+	for name, fRules := range rules {
+		if name == RootName {
+			name = ""
+		}
+		expand(name, fRules, nil)
+	}
+	return expanded
+}
+
+// PrefixRules returns rules keyed by prefix and their keys, e.g. PrefixRules("billing", Rules{"city": {"required"}})
+// returns Rules{"billing.city": {"required"}}. Rules of RootName are the rules of prefix.
+func PrefixRules(prefix string, rules Rules) Rules {
+	prefixed := make(Rules, len(rules))
+	for k, r := range rules {
+		if k == RootName && prefix != "" {
+			k = prefix
+		} else {
+			k = joinName(prefix, k)
+		}
+		prefixed[k] = append([]string{}, r...)
+	}
+	return prefixed
+}
+
+// MergeRules returns the rules of all rules, rules of the same keys are appended in the order of rules.
+func MergeRules(rules ...Rules) Rules {
+	merged := make(Rules)
+	for _, rs := range rules {
+		for k, r := range rs {
+			if _, ok := merged[k]; !ok {
+				merged[k] = []string{}
+			}
+			merged[k] = append(merged[k], r...)
+		}
+	}
+	return merged
+}
+
+// ExtendRules returns the rules of base and of exts, rules of the same keys are replaced by the rules of the last
+// of exts that has them, e.g. ExtendRules(base, Rules{"password": {"skip"}}) doesn't validate password.
+func ExtendRules(base Rules, exts ...Rules) Rules {
+	extended := deepCopyRules(base)
+	for _, ext := range exts {
+		for k, r := range ext {
+			extended[k] = append([]string{}, r...)
+		}
+	}
+	return extended
+}
+
+// deepCopyRules returns a copy of rules that doesn't share their lists of rules.
+func deepCopyRules(rules Rules) Rules {
+	copied := make(Rules, len(rules))
+	for k, r := range rules {
+		copied[k] = append([]string{}, r...)
+	}
+	return copied
+}
+
+func init() {
+	AddRule("schema", schemaRule, "[name] must be an object of schema [ruleVal]")
+}
@@ -0,0 +1,353 @@
+package valdn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterSchema("test_address", Rules{"city": {"required", "minLen:2"}, "zip": {"len:5"}})
+	RegisterSchema("test_order", Rules{"billing": {"required", "schema:test_address"}, "shipping": {"schema:test_address"}})
+	RegisterSchema("test_struct_address", Rules{"City": {"required", "minLen:2"}})
+	RegisterSchema("test_node", Rules{"name": {"required"}, "children": {"kind:slice"}, "children.*": {"schema:test_node"}})
+}
+
+func Test_RegisterSchema(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		rules     Rules
+		wantPanic bool
+	}{
+		{
+			name:   "test register schema",
+			schema: "test_register_schema",
+			rules:  Rules{"amount": {"required", "numeric"}, "currency": {"len:3"}},
+		},
+		{
+			name:   "test register schema of schema that is not registered yet",
+			schema: "test_register_schema_forward",
+			rules:  Rules{"contact": {"schema:test_contact"}},
+		},
+		{
+			name:      "test register schema already exist",
+			schema:    "test_register_schema",
+			rules:     Rules{},
+			wantPanic: true,
+		},
+		{
+			name:      "test register schema of unknown rule",
+			schema:    "test_register_schema_unknown",
+			rules:     Rules{"amount": {"required", "unknown"}},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("RegisterSchema() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			RegisterSchema(tt.schema, tt.rules)
+			if got := registeredSchemas[tt.schema]; !reflect.DeepEqual(got, tt.rules) {
+				t.Errorf("RegisterSchema() registered %v, want %v", got, tt.rules)
+			}
+		})
+	}
+}
+
+func Test_schemaRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		val       interface{}
+		ruleVal   string
+		wantErr   bool
+		wantPanic bool
+	}{
+		{
+			name:    "test schema rule of map",
+			val:     map[string]interface{}{"city": "Cairo"},
+			ruleVal: "test_address",
+			wantErr: false,
+		},
+		{
+			name:    "test schema rule of slice",
+			val:     []interface{}{},
+			ruleVal: "test_node",
+			wantErr: false,
+		},
+		{
+			name:    "test schema rule of string",
+			val:     "Cairo",
+			ruleVal: "test_address",
+			wantErr: true,
+		},
+		{
+			name:      "test schema rule of unknown schema",
+			val:       map[string]interface{}{},
+			ruleVal:   "unknown",
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("schemaRule() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if err := schemaRule("address", tt.val, tt.ruleVal); (err != nil) != tt.wantErr {
+				t.Errorf("schemaRule() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ValidateCollection_schemas(t *testing.T) {
+	type address struct {
+		City string
+	}
+	type user struct {
+		Home address `valdn:"schema:test_struct_address"`
+	}
+	tests := []struct {
+		name      string
+		val       interface{}
+		rules     Rules
+		want      Errors
+		wantPanic bool
+	}{
+		{
+			name: "test validate collection with schemas",
+			val: map[string]interface{}{
+				"billing":  map[string]interface{}{"city": "C", "zip": "12345"},
+				"shipping": map[string]interface{}{"zip": "123"},
+			},
+			rules: Rules{"billing": {"schema:test_address"}, "shipping": {"schema:test_address"}},
+			want: Errors{
+				"billing.city":  GetErrMsg("minLen", "2", "billing.city", "C"),
+				"shipping.city": GetErrMsg("required", "", "shipping.city", ""),
+				"shipping.zip":  GetErrMsg("len", "5", "shipping.zip", "123"),
+			},
+		},
+		{
+			name: "test validate collection with schemas of schemas",
+			val: map[string]interface{}{
+				"order": map[string]interface{}{"billing": map[string]interface{}{"city": "Cairo", "zip": "1"}},
+			},
+			rules: Rules{"order": {"required", "schema:test_order"}, "order.billing.zip": {"int"}},
+			want: Errors{
+				"order.billing.zip": GetErrMsg("int", "", "order.billing.zip", "1"),
+			},
+		},
+		{
+			name:  "test validate collection with schema of absent object",
+			val:   map[string]interface{}{},
+			rules: Rules{"order": {"schema:test_order"}},
+			want:  Errors{},
+		},
+		{
+			name: "test validate collection with recursive schema",
+			val: map[string]interface{}{"name": "root", "children": []interface{}{
+				map[string]interface{}{"name": "a", "children": []interface{}{map[string]interface{}{}}},
+				map[string]interface{}{"name": "b"},
+			}},
+			rules: Rules{RootName: {"schema:test_node"}},
+			want: Errors{
+				"children.0.children.0.name": GetErrMsg("required", "", "children.0.children.0.name", ""),
+			},
+		},
+		{
+			name:  "test validate collection with schema of items",
+			val:   map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"city": "Cairo"}, "Giza"}},
+			rules: Rules{"addresses.*": {"schema:test_address"}},
+			want: Errors{
+				"addresses.1": GetErrMsg("schema", "test_address", "addresses.1", "Giza"),
+			},
+		},
+		{
+			name: "test validate collection with schema of struct tag",
+			val:  user{Home: address{City: "C"}},
+			want: Errors{"Home.City": GetErrMsg("minLen", "2", "Home.City", "C")},
+		},
+		{
+			name:      "test validate collection with unknown schema",
+			val:       map[string]interface{}{"billing": map[string]interface{}{}},
+			rules:     Rules{"billing": {"schema:unknown"}},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("ValidateCollection() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := ValidateCollection(tt.val, tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateCollection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateJSONStream_schemas(t *testing.T) {
+	val := `{"name": "root", "children": [{"name": "a"}, {"children": []}]}`
+	got := ValidateJSONStream(strings.NewReader(val), Rules{RootName: {"schema:test_node"}})
+	want := Errors{"children.1.name": GetErrMsg("required", "", "children.1.name", "")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateJSONStream() = %v, want %v", got, want)
+	}
+}
+
+func Test_schemaRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		want  Rules
+	}{
+		{
+			name:  "test schema rules of field",
+			rules: Rules{"billing": {"required", "schema:test_address"}, "note": {"kind:string"}},
+			want: Rules{
+				"billing":      {"required", "schema:test_address"},
+				"billing.city": {"required", "minLen:2"},
+				"billing.zip":  {"len:5"},
+				"note":         {"kind:string"},
+			},
+		},
+		{
+			name:  "test schema rules of root and nested schemas",
+			rules: Rules{RootName: {"schema:test_order"}, "shipping.zip": {"required"}},
+			want: Rules{
+				RootName:        {"schema:test_order"},
+				"billing":       {"required", "schema:test_address"},
+				"billing.city":  {"required", "minLen:2"},
+				"billing.zip":   {"len:5"},
+				"shipping":      {"schema:test_address"},
+				"shipping.city": {"required", "minLen:2"},
+				"shipping.zip":  {"required", "len:5"},
+			},
+		},
+		{
+			name:  "test schema rules of schema of itself",
+			rules: Rules{"tree": {"schema:test_node"}},
+			want: Rules{
+				"tree":            {"schema:test_node"},
+				"tree.name":       {"required"},
+				"tree.children":   {"kind:slice"},
+				"tree.children.*": {"schema:test_node"},
+			},
+		},
+		{
+			name:  "test schema rules of unknown schema",
+			rules: Rules{"billing": {"schema:test_unknown_schema"}},
+			want:  Rules{"billing": {"schema:test_unknown_schema"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaRules(tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("schemaRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateJSON_schemaUnknownFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		val       string
+		rules     Rules
+		want      Errors
+		wantPanic bool
+	}{
+		{
+			name:  "test known fields of schema",
+			val:   `{"billing":{"city":"Cairo","zip":"11311"}}`,
+			rules: Rules{"billing": {"schema:test_address"}},
+			want:  Errors{},
+		},
+		{
+			name:      "test unknown field of schema",
+			val:       `{"billing":{"zip":"1","country":"EG"}}`,
+			rules:     Rules{"billing": {"schema:test_address"}},
+			wantPanic: true,
+		},
+		{
+			name:      "test unknown field of schema of root",
+			val:       `{"billing":{"city":"Cairo"},"shipping":{"city":"Cairo","country":"EG"}}`,
+			rules:     Rules{RootName: {"schema:test_order"}},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("ValidateJSON() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := ValidateJSON(tt.val, tt.rules, WithDisallowUnknownFields()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_PrefixRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		rules  Rules
+		want   Rules
+	}{
+		{
+			name:   "test prefix rules",
+			prefix: "billing",
+			rules:  Rules{RootName: {"required"}, "city": {"required"}, "lines.*": {"maxLen:60"}},
+			want:   Rules{"billing": {"required"}, "billing.city": {"required"}, "billing.lines.*": {"maxLen:60"}},
+		},
+		{
+			name:   "test prefix rules of empty prefix",
+			prefix: "",
+			rules:  Rules{RootName: {"required"}, "city": {"required"}},
+			want:   Rules{RootName: {"required"}, "city": {"required"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrefixRules(tt.prefix, tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PrefixRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_MergeRules(t *testing.T) {
+	a := Rules{"name": {"required"}, "age": {"int"}}
+	b := Rules{"name": {"minLen:3"}, "email": {"email"}}
+	got := MergeRules(a, b)
+	want := Rules{"name": {"required", "minLen:3"}, "age": {"int"}, "email": {"email"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeRules() = %v, want %v", got, want)
+	}
+	got["age"][0] = "uint"
+	if a["age"][0] != "int" {
+		t.Errorf("MergeRules() shares the rules of %v", a)
+	}
+}
+
+func Test_ExtendRules(t *testing.T) {
+	base := Rules{"name": {"required"}, "password": {"required", "minLen:8"}}
+	got := ExtendRules(base, Rules{"password": {"skip"}}, Rules{"name": {"minLen:3"}, "age": {"int"}})
+	want := Rules{"name": {"minLen:3"}, "password": {"skip"}, "age": {"int"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtendRules() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(base, Rules{"name": {"required"}, "password": {"required", "minLen:8"}}) {
+		t.Errorf("ExtendRules() changed base: %v", base)
+	}
+}
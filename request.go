@@ -3,45 +3,72 @@ package valdn
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math/big"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
 )
 
-const defaultMaxMemory = 32 << 20 // 32 MB
+const (
+	defaultMaxMemory = 32 << 20 // 32 MB
+	maxFormBytes     = 10 << 20 // 10 MB, the max size of url encoded forms
+)
 
-func parseReqVal(val string) interface{} {
-	i, err := strconv.ParseInt(val, 10, 64)
-	if err == nil {
-		return i
+var (
+	// ErrUnsupportedMediaType is wrapped by RequestError when the request's content type is not supported or not allowed.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrUnsupportedCharset is wrapped by RequestError when the request's charset can't be decoded.
+	ErrUnsupportedCharset = errors.New("unsupported charset")
+)
+
+// RequestError is the panic value raised by ValidateRequest when the request can't be parsed.
+// Status is the HTTP status code that fits the failure, e.g. 400 for a malformed body.
+type RequestError struct {
+	Status int
+	Err    error
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// newRequestError wraps err in RequestError with the status code that fits it.
+func newRequestError(err error) *RequestError {
+	var re *RequestError
+	if errors.As(err, &re) {
+		return re
 	}
-	f, err := strconv.ParseFloat(val, 64)
-	if err == nil {
-		return f
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = fmt.Errorf("%w: more than %v bytes", ErrBodyTooLarge, maxBytesErr.Limit)
 	}
-	c, err := strconv.ParseComplex(val, 128)
-	if err == nil {
-		return c
+	status := http.StatusBadRequest
+	if s := limitStatus(err); s != 0 {
+		status = s
+	} else if errors.Is(err, http.ErrNotMultipart) || errors.Is(err, ErrUnsupportedMediaType) || errors.Is(err, ErrUnsupportedCharset) {
+		status = http.StatusUnsupportedMediaType
 	}
-	return val
+	return &RequestError{Status: status, Err: err}
 }
 
+// parseJSONVal converts JSON numbers in val to Go numbers, see parseJSONNumber.
 func parseJSONVal(val interface{}) interface{} {
-	if v, ok := val.(float64); ok {
-		s := toString(v)
-		isFloat := false
-		for i := len(s); i > 0; i-- {
-			if s[i-1] == '.' {
-				isFloat = true
-				break
-			}
-		}
-		if !isFloat {
-			return int(v)
-		}
-		return v
+	if v, ok := val.(json.Number); ok {
+		return parseJSONNumber(v)
 	}
 
 	if v, ok := val.([]interface{}); ok {
@@ -63,11 +90,179 @@ func parseJSONVal(val interface{}) interface{} {
 	return val
 }
 
+// maxExactExponent is the max exponent of a decimal JSON number that is converted to big.Rat,
+// numbers of greater exponents would take too long to convert, so they're kept as float64 (e.g. +Inf).
+const maxExactExponent = 1000
+
+// parseJSONNumber converts n without losing precision.
+// Integers are converted to int, or *big.Int if they overflow int.
+// Decimals are converted to float64 if it holds the same decimal value (e.g. 0.1 and 1.0), or *big.Rat if it doesn't.
+func parseJSONNumber(n json.Number) interface{} {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 0); err == nil {
+			return int(i)
+		}
+		if i, ok := new(big.Int).SetString(s, 10); ok {
+			return i
+		}
+		return s
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		if exp, expErr := strconv.Atoi(s[i+1:]); expErr != nil || exp > maxExactExponent || exp < -maxExactExponent {
+			return f
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return f
+	}
+	if err == nil {
+		if fr, _ := interfaceToRat(f); fr != nil && fr.Cmp(r) == 0 {
+			return f
+		}
+	}
+	return r
+}
+
+// kindTypes are the types request values can be converted to by kind rule.
+var kindTypes = map[string]reflect.Type{
+	"string":  reflect.TypeOf(""),
+	"bool":    reflect.TypeOf(false),
+	"int":     reflect.TypeOf(int(0)),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+}
+
+// convertToKind converts val to kind.
+// It returns error if val can't be converted.
+func convertToKind(val string, kind string) (interface{}, error) {
+	t, ok := kindTypes[kind]
+	if !ok {
+		return nil, fmt.Errorf("can't convert to kind %v", kind)
+	}
+	var v interface{}
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		v = val
+	case reflect.Bool:
+		v, err = strconv.ParseBool(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err = strconv.ParseInt(val, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err = strconv.ParseUint(val, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(val, t.Bits())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(v).Convert(t).Interface(), nil
+}
+
+// convertReqVal converts val by the first rule of rules that declares a type:
+// int, uint and kind:[int kinds] convert to integers, float, ufloat and kind:[float kinds] convert to floats,
+// numeric, min, max and between convert to an integer or a float, complex converts to complex128,
+// bool and kind:bool convert to bool and time converts to time.Time using the layout of timeFormat rule or RFC3339.
+// If no rule declares a type val is kept as a string.
+// It returns error message of the rule that declares the type if val can't be converted.
+func convertReqVal(name string, val string, rules []string) (interface{}, error) {
+	rules = expandAliases(rules)
+	for _, r := range rules {
+		rName, rVal := splitRuleNameAndRuleValue(r)
+		var v interface{}
+		var err error
+		switch rName {
+		case "int":
+			v, err = strconv.ParseInt(val, 10, 64)
+		case "uint":
+			v, err = strconv.ParseUint(val, 10, 64)
+		case "float", "ufloat":
+			v, err = strconv.ParseFloat(val, 64)
+		case "numeric", "min", "max", "between":
+			if v, err = strconv.ParseInt(val, 10, 64); err != nil {
+				v, err = strconv.ParseFloat(val, 64)
+			}
+			if err != nil {
+				return nil, errors.New(GetErrMsg("numeric", "", name, val))
+			}
+		case "complex":
+			v, err = strconv.ParseComplex(val, 128)
+		case "bool":
+			v, err = strconv.ParseBool(val)
+		case "kind":
+			if _, ok := kindTypes[rVal]; !ok {
+				continue
+			}
+			v, err = convertToKind(val, rVal)
+		case "time":
+			layout := time.RFC3339
+			for _, tr := range rules {
+				if trName, trVal := splitRuleNameAndRuleValue(tr); trName == "timeFormat" {
+					layout = trVal
+				}
+			}
+			v, err = time.Parse(layout, val)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, errors.New(GetErrMsg(rName, rVal, name, val))
+		}
+		return v, nil
+	}
+	return val, nil
+}
+
+// convertReqVals converts string values of m (and its nested maps and slices) by their rules, see convertReqVal.
+// Errors of values that can't be converted are added to errs named by their paths in parPath (e.g. the namespace of
+// m), and the values are kept as they are.
+func convertReqVals(m map[string]interface{}, rules Rules, parPath Path, errs Errors) {
+	v := createNewValidation(rules)
+	// name and parName are the dotted names of p and of its parent
+	var convert func(val interface{}, p Path, name, parName string) interface{}
+	convert = func(val interface{}, p Path, name, parName string) interface{} {
+		switch typedVal := val.(type) {
+		case string:
+			errName := append(parPath[:len(parPath):len(parPath)], p...).String()
+			converted, err := convertReqVal(errName, typedVal, v.getNestedRules(name, parName))
+			if err != nil {
+				errs[errName] = err.Error()
+				return val
+			}
+			return converted
+		case map[string]interface{}:
+			for k, nested := range typedVal {
+				typedVal[k] = convert(nested, p.child(k), p.childName(name, k), name)
+			}
+		case []interface{}:
+			for i, nested := range typedVal {
+				typedVal[i] = convert(nested, p.child(i), p.childName(name, i), name)
+			}
+		}
+		return val
+	}
+	for k, val := range m {
+		m[k] = convert(val, Path{k}, k, "")
+	}
+}
+
 func stringSliceToInterface(s []string) []interface{} {
 	var newSlice []interface{}
 	for _, v := range s {
-		newSlice = append(newSlice, parseReqVal(v))
-
+		newSlice = append(newSlice, v)
 	}
 	return newSlice
 }
@@ -80,122 +275,728 @@ func fhsSliceToInterface(s []*multipart.FileHeader) []interface{} {
 	return newSlice
 }
 
-func parseJSON(r *http.Request, m map[string]interface{}) {
+// decodeCharset converts b from charset to UTF-8.
+// It returns error if charset is not known.
+func decodeCharset(b []byte, charset string) ([]byte, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii":
+		return b, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedCharset, charset)
+	}
+	return enc.NewDecoder().Bytes(b)
+}
+
+// isJSONMediaType reports weather mediaType is application/json or has +json suffix (e.g. application/vnd.api+json).
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isXMLMediaType reports weather mediaType is application/xml, text/xml or has +xml suffix (e.g. application/atom+xml).
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// isMediaTypeAllowed reports weather mediaType is one of allowed.
+// An allowed item that starts with + (e.g. +json) allows every media type with that suffix.
+func isMediaTypeAllowed(mediaType string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == mediaType || (strings.HasPrefix(a, "+") && strings.HasSuffix(mediaType, a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseJSON parses the JSON body of r into m and returns its root.
+// Fields of an object root are added to m by their names, and items of an array root by their indexes.
+func parseJSON(r *http.Request, rules Rules, m map[string]interface{}, charset string, c *config) interface{} {
 	// double stream request body, and reassign it at the end, so it can be read later
 	buf := &bytes.Buffer{}
 	tee := io.TeeReader(r.Body, buf)
 
 	b, err := io.ReadAll(tee)
 	if err != nil {
-		panic(err)
+		panic(newRequestError(err))
 	}
-	err = json.Unmarshal(b, &m)
+	b, err = decodeCharset(b, charset)
 	if err != nil {
-		panic(err)
+		panic(newRequestError(err))
 	}
-
-	for k, v := range m {
-		m[k] = parseJSONVal(v)
+	root, err := decodeJSON(b, rules, c)
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	switch v := root.(type) {
+	case map[string]interface{}:
+		for k, i := range v {
+			m[k] = i
+		}
+	case []interface{}:
+		for idx, i := range v {
+			m[toString(idx)] = i
+		}
 	}
 
 	r.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+	return root
 }
 
-func parseFormData(r *http.Request, rules Rules, m map[string]interface{}) {
-	err := r.ParseMultipartForm(defaultMaxMemory)
+func parseXML(r *http.Request, rules Rules, m map[string]interface{}, charset string, l Limits) {
+	// double stream request body, and reassign it at the end, so it can be read later
+	buf := &bytes.Buffer{}
+	b, err := io.ReadAll(io.TeeReader(r.Body, buf))
 	if err != nil {
-		panic(err)
+		panic(newRequestError(err))
 	}
-	for k := range rules {
-		// convert files and values to interface, so it can be merged together
-		v := stringSliceToInterface(r.MultipartForm.Value[k])
-		f := fhsSliceToInterface(r.MultipartForm.File[k])
+	doc, err := decodeXML(b, rules, l, charset)
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	for k, v := range doc {
+		m[k] = v
+	}
+	r.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+}
+
+func parseFormData(r *http.Request, rules Rules, m map[string]interface{}, l Limits) {
+	form, err := readMultipartForm(r, l)
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	r.MultipartForm = form
+	r.PostForm = form.Value
+	_ = r.ParseForm()
+	// convert files and values to interface, so fields that have both can be merged together
+	fields := make(map[string][]interface{}, len(r.MultipartForm.Value)+len(r.MultipartForm.File))
+	for k, f := range r.MultipartForm.File {
+		fields[k] = fhsSliceToInterface(f)
+	}
+	for k, v := range r.MultipartForm.Value {
+		fields[k] = append(fields[k], stringSliceToInterface(v)...)
+	}
+	decoded, err := decodeFields(fields, rules, l)
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	if err = l.checkValue("", decoded, 0); err != nil {
+		panic(newRequestError(err))
+	}
+	for k, v := range decoded {
+		m[k] = v
+	}
+}
+
+// readMultipartForm reads the multipart form of r like r.ParseMultipartForm, but its parts are counted while they're
+// read, so it stops at the first part that exceeds the limits of l before the rest of the body is read.
+func readMultipartForm(r *http.Request, l Limits) (*multipart.Form, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	// counted parts are written to a pipe, and read back by multipart.Reader.ReadForm
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	copied := make(chan error, 1)
+	go func() {
+		err := copyParts(mr, mw, l)
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = pw.CloseWithError(err)
+		copied <- err
+	}()
+	form, err := multipart.NewReader(pr, mw.Boundary()).ReadForm(l.maxMemory())
+	_ = pr.Close()
+	// errors of reading the body (e.g. limits) are reported rather than the errors they caused to ReadForm
+	if copyErr := <-copied; copyErr != nil && copyErr != io.ErrClosedPipe {
+		if form != nil {
+			_ = form.RemoveAll()
+		}
+		return nil, copyErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return form, nil
+}
 
+// copyParts writes the parts of mr to mw, and returns an error once they exceed MaxParts, MaxFiles or MaxFormFields.
+func copyParts(mr *multipart.Reader, mw *multipart.Writer, l Limits) error {
+	parts, files, fields := 0, 0, 0
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		parts++
+		if l.MaxParts > 0 && parts > l.MaxParts {
+			return fmt.Errorf("%w: more than %v", ErrTooManyParts, l.MaxParts)
+		}
 		switch {
-		case len(v) > 0 && len(f) == 0:
-			// if no files exists
-			// and values length is 1 add it as a string
-			// if length is greater than 1 add it as a slice of strings
-			if len(v) > 1 {
-				m[k] = stringSliceToInterface(r.MultipartForm.Value[k])
-			} else {
-				m[k] = parseReqVal(r.PostForm.Get(k))
+		case p.FormName() == "":
+		case p.FileName() != "":
+			files++
+			if l.MaxFiles > 0 && files > l.MaxFiles {
+				return fmt.Errorf("%w: more than %v", ErrTooManyFiles, l.MaxFiles)
 			}
-		case len(f) > 0 && len(v) == 0:
-			// if no values exists
-			// and files length is 1 add it as a file
-			// if length is greater than 1 add it as a slice of files
-			if len(f) > 1 {
-				m[k] = r.MultipartForm.File[k]
-			} else {
-				_, m[k], _ = r.FormFile(k)
+		default:
+			fields++
+			if l.MaxFormFields > 0 && fields > l.MaxFormFields {
+				return fmt.Errorf("%w: more than %v", ErrTooManyFormFields, l.MaxFormFields)
 			}
-		case len(v) > 0 && len(f) > 0:
-			// if both files and values with that name exists, merge them in one slice
-			m[k] = append(f, v...)
+		}
+		w, err := mw.CreatePart(p.Header)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(w, p); err != nil {
+			return err
 		}
 	}
 }
 
-func parseURLEncoded(r *http.Request, rules Rules, m map[string]interface{}) {
-	if err := r.ParseForm(); err != nil {
-		panic(err)
+// formFields returns the fields of url encoded form b by their raw keys, without decoding their values.
+func formFields(b []byte) map[string][]string {
+	fields := make(map[string][]string)
+	for len(b) > 0 {
+		var pair []byte
+		pair, b, _ = bytes.Cut(b, []byte("&"))
+		if len(pair) == 0 {
+			continue
+		}
+		key, _, _ := bytes.Cut(pair, []byte("="))
+		fields[string(key)] = append(fields[string(key)], "")
 	}
+	return fields
+}
 
-	for k := range rules {
-		v := r.PostForm[k]
-		if len(v) > 1 {
-			m[k] = stringSliceToInterface(r.PostForm[k])
+func parseURLEncoded(r *http.Request, rules Rules, m map[string]interface{}, charset string, l Limits) {
+	// the same checks as r.ParseForm
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if _, _, err := mime.ParseMediaType(ct); err != nil {
+			panic(newRequestError(err))
+		}
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxFormBytes+1))
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	if len(b) > maxFormBytes {
+		panic(newRequestError(errors.New("http: POST too large")))
+	}
+	// fields are counted before they're parsed
+	if err = l.checkFormFields(formFields(b)); err != nil {
+		panic(newRequestError(err))
+	}
+	if r.PostForm, err = url.ParseQuery(string(b)); err != nil {
+		panic(newRequestError(err))
+	}
+	if err = r.ParseForm(); err != nil {
+		panic(newRequestError(err))
+	}
+	for k, values := range r.PostForm {
+		for i, v := range values {
+			b, err := decodeCharset([]byte(v), charset)
+			if err != nil {
+				panic(newRequestError(err))
+			}
+			values[i] = string(b)
+		}
+		r.PostForm[k] = values
+	}
+	decoded, err := decodeParams(r.PostForm, rules, l)
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	if err := l.checkValue("", decoded, 0); err != nil {
+		panic(newRequestError(err))
+	}
+	for k, v := range decoded {
+		m[k] = v
+	}
+}
+
+// MergePolicy decides what happens to url params that have the same name as body values.
+type MergePolicy int
+
+const (
+	// MergeAppend merges url params and body values with the same name in one slice.
+	MergeAppend MergePolicy = iota
+	// MergeBodyWins keeps body values and drops url params with the same name.
+	MergeBodyWins
+	// MergeQueryWins replaces body values with url params with the same name.
+	MergeQueryWins
+	// MergeSeparate doesn't merge url params with body values, url params are validated by query.* rules only.
+	MergeSeparate
+	// MergeReject reports an error for every url param that has the same name as a body value.
+	MergeReject
+)
+
+// splitParamKey splits param key in dotted or bracket notation into its segments,
+// e.g. items.0.sku, items[0][sku] and items[0].sku into items, 0 and sku.
+// Empty brackets (tags[]) make an empty segment, and malformed bracket keys are returned as they are.
+func splitParamKey(key string) []string {
+	i := strings.IndexAny(key, ".[")
+	if i <= 0 {
+		return []string{key}
+	}
+	segments := []string{key[:i]}
+	for rest := key[i:]; rest != ""; {
+		if rest[0] == '.' {
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+			continue
+		}
+		end := strings.IndexByte(rest, ']')
+		if end < 0 || (end+1 < len(rest) && rest[end+1] != '[' && rest[end+1] != '.') {
+			return []string{key}
+		}
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return segments
+}
+
+// setParam adds values to m under the nested path segments.
+// Nested values are added to maps, maps of indexes are converted to slices by listifyParams.
+func setParam(m map[string]interface{}, segments []string, values []interface{}) {
+	if len(segments) == 1 {
+		if len(values) > 1 {
+			m[segments[0]] = values
 		} else {
-			m[k] = parseReqVal(r.PostForm.Get(k))
+			m[segments[0]] = values[0]
+		}
+		return
+	}
+	child, ok := m[segments[0]].(map[string]interface{})
+	if !ok {
+		if _, exists := m[segments[0]]; exists {
+			// a param can't be a value and a parent at the same time
+			return
+		}
+		child = make(map[string]interface{})
+		m[segments[0]] = child
+	}
+	if segments[1] == "" {
+		// every value of empty brackets is a new slice item
+		for _, v := range values {
+			setParam(child, append([]string{toString(len(child))}, segments[2:]...), []interface{}{v})
+		}
+		return
+	}
+	setParam(child, segments[1:], values)
+}
+
+// listifyParams converts maps whose keys are the indexes 0 to len-1 to slices.
+func listifyParams(val interface{}) interface{} {
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return val
+	}
+	for k, v := range m {
+		m[k] = listifyParams(v)
+	}
+	s := make([]interface{}, len(m))
+	for k, v := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || toString(i) != k {
+			return m
 		}
+		s[i] = v
+	}
+	return s
+}
+
+// decodeParams decodes params in plain, dotted and bracket notations
+// (tags[]=a, filter.status=open, filter[status]=open, items[0][sku]=x) into nested maps and slices.
+// Only params whose names start with a name in rules are decoded, unless rules have root rule .* (or *).
+// It returns ErrTooDeep if a decoded param is nested deeper than the limits of l allow, see Limits.checkParamDepth.
+func decodeParams(params map[string][]string, rules Rules, l Limits) (map[string]interface{}, error) {
+	fields := make(map[string][]interface{}, len(params))
+	for k, v := range params {
+		fields[k] = stringSliceToInterface(v)
 	}
+	return decodeFields(fields, rules, l)
 }
 
-func parseURLParams(r *http.Request, rules Rules, m map[string]interface{}) {
+// decodeFields decodes fields the same way as decodeParams, fields may have values of any type (e.g. files).
+func decodeFields(fields map[string][]interface{}, rules Rules, l Limits) (map[string]interface{}, error) {
+	_, all := rules[".*"]
+	if _, ok := rules["*"]; ok {
+		all = true
+	}
+	roots := make(map[string]bool, len(rules))
 	for k := range rules {
-		q, ok := r.URL.Query()[k]
+		roots[strings.SplitN(k, ".", 2)[0]] = true
+	}
+
+	m := make(map[string]interface{})
+	for k, values := range fields {
+		segments := splitParamKey(k)
+		if len(values) == 0 || (!all && !roots[segments[0]]) {
+			continue
+		}
+		// params are checked before they're nested, so keys of any depth don't build maps
+		if err := l.checkParamDepth(segments); err != nil {
+			return nil, err
+		}
+		setParam(m, segments, values)
+	}
+	for k, v := range m {
+		m[k] = listifyParams(v)
+	}
+	return m, nil
+}
+
+func parseURLParams(r *http.Request, rules Rules, m map[string]interface{}, l Limits) {
+	q, err := decodeParams(r.URL.Query(), rules, l)
+	if err != nil {
+		panic(newRequestError(err))
+	}
+	mergeReqVals(m, q, MergeAppend)
+}
+
+// mergeReqVals adds src values to dst by policy, and returns the names of src values dst already has.
+// MergeSeparate policy is handled by the caller, so it's treated as MergeAppend.
+func mergeReqVals(dst map[string]interface{}, src map[string]interface{}, policy MergePolicy) []string {
+	var duplicates []string
+	for k, v := range src {
+		dstVal, ok := dst[k]
 		if !ok {
-			return
+			dst[k] = v
+			continue
 		}
-		param := stringSliceToInterface(q)
-		if _, ok := m[k]; !ok {
-			// if param exists and no values exists in the map with same name add param value to the map
-			l := len(param)
-			if l == 1 {
-				m[k] = param[0]
-			} else if l > 1 {
-				m[k] = param
-			}
+		duplicates = append(duplicates, k)
+
+		switch policy {
+		case MergeBodyWins, MergeReject:
+			continue
+		case MergeQueryWins:
+			dst[k] = v
+			continue
+		}
+		merged, ok := dstVal.([]interface{})
+		if !ok {
+			merged = []interface{}{dstVal}
+		}
+		if s, ok := v.([]interface{}); ok {
+			dst[k] = append(merged, s...)
 		} else {
-			// if param exists and values exists in the map merge both param values and map values
-			if v, ok := m[k].([]interface{}); ok {
-				m[k] = append(v, param...)
+			dst[k] = append(merged, v)
+		}
+	}
+	return duplicates
+}
+
+// requestNamespaces are the rule name prefixes that select where a request value comes from.
+var requestNamespaces = []string{"header", "cookie", "path", "query", "body"}
+
+// activeNamespaces returns the request namespaces rules of c may use, see WithNamespaces.
+// MergeSeparate policy uses the query namespace even if namespaces are not enabled.
+func (c *config) activeNamespaces() []string {
+	switch {
+	case c.namespaces:
+		return requestNamespaces
+	case c.mergePolicy == MergeSeparate:
+		return []string{"query"}
+	}
+	return nil
+}
+
+// isNamespaced reports weather name starts with one of namespaces.
+func isNamespaced(name string, namespaces []string) bool {
+	for _, ns := range namespaces {
+		if strings.HasPrefix(name, ns+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceRules returns rules whose names start with namespace and a dot, without that prefix.
+func namespaceRules(rules Rules, namespace string) Rules {
+	var nsRules Rules
+	for k, v := range rules {
+		if name := strings.TrimPrefix(k, namespace+"."); name != k {
+			if nsRules == nil {
+				nsRules = make(Rules)
 			}
-			if v, ok := m[k].(string); ok {
-				s := []interface{}{v}
-				m[k] = append(s, param...)
+			nsRules[name] = v
+		}
+	}
+	return nsRules
+}
+
+// setReqVals adds values to m as a single value if there is one value, or as a slice if there are many.
+func setReqVals(m map[string]interface{}, k string, values []string) {
+	switch {
+	case len(values) == 1:
+		m[k] = values[0]
+	case len(values) > 1:
+		m[k] = stringSliceToInterface(values)
+	}
+}
+
+func parseHeaders(r *http.Request, rules Rules, m map[string]interface{}) {
+	for k := range rules {
+		setReqVals(m, k, r.Header.Values(k))
+	}
+}
+
+func parseCookies(r *http.Request, rules Rules, m map[string]interface{}) {
+	for k := range rules {
+		var values []string
+		for _, c := range r.CookiesNamed(k) {
+			values = append(values, c.Value)
+		}
+		setReqVals(m, k, values)
+	}
+}
+
+func parsePathValues(r *http.Request, rules Rules, m map[string]interface{}) {
+	for k := range rules {
+		if v := r.PathValue(k); v != "" {
+			m[k] = v
+		}
+	}
+}
+
+// ruleSources returns the sources allowed by source rule of rules, or nil if there is no source rule.
+func ruleSources(rules []string) []string {
+	for _, r := range expandAliases(rules) {
+		if rName, rVal := splitRuleNameAndRuleValue(r); rName == "source" {
+			return strings.Split(rVal, ",")
+		}
+	}
+	return nil
+}
+
+// expectsMany reports weather rules of name expect a slice or an array, by kind rules or rules of nested fields.
+func expectsMany(rules Rules, name string) bool {
+	if kindsMany(rules[name]) {
+		return true
+	}
+	for k := range rules {
+		if strings.HasPrefix(k, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// kindsMany reports weather rules have a kind rule of slice or array.
+func kindsMany(rules []string) bool {
+	for _, r := range expandAliases(rules) {
+		rName, rVal := splitRuleNameAndRuleValue(r)
+		if (rName == "kind" || rName == "kindIn") && (strings.Contains(rVal, "slice") || strings.Contains(rVal, "array")) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSource removes values of src (parsed from source) that their source rule doesn't allow to come from source,
+// values of nested objects and arrays included. In strict mode it also removes values that have many values while
+// their rules expect one, at any depth.
+// Errors of removed values are added to errs, named by namespace ns if it's not empty (e.g. header.X-Request-ID).
+func checkSource(src map[string]interface{}, ns string, source string, rules Rules, strict bool, errs Errors) {
+	s := sourceCheck{ns: ns, source: source, rules: rules, strict: strict, errs: errs}
+	s.object(src, "")
+}
+
+// sourceCheck checks where request values come from, see checkSource.
+type sourceCheck struct {
+	ns     string
+	source string
+	rules  Rules
+	strict bool
+	errs   Errors
+}
+
+// object removes the values of m, named parName, that are not allowed.
+func (s sourceCheck) object(m map[string]interface{}, parName string) {
+	for k, v := range m {
+		if !s.value(v, joinName(parName, k), parName) {
+			delete(m, k)
+		}
+	}
+}
+
+// value reports weather v named name, of parent parName, is allowed, values of v that are not allowed are removed.
+func (s sourceCheck) value(v interface{}, name string, parName string) bool {
+	fRules, ok := s.rules[name]
+	if !ok {
+		fRules = s.rules[joinName(parName, "*")]
+	}
+	if sources := ruleSources(fRules); sources != nil && !isIn(s.source, sources) {
+		errName := joinName(s.ns, name)
+		s.errs[errName] = GetErrMsg("source", strings.Join(sources, ","), errName, v)
+		return false
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		s.object(v, name)
+	case []interface{}:
+		if s.strict && !kindsMany(fRules) && !expectsMany(s.rules, name) {
+			errName := joinName(s.ns, name)
+			s.errs[errName] = fmt.Sprintf("%v must have one value", errName)
+			return false
+		}
+		for i, item := range v {
+			// items come from the same source, so an item that is not allowed removes its array
+			if !s.value(item, joinName(name, strconv.Itoa(i)), name) {
+				return false
 			}
 		}
 	}
+	return true
 }
 
-func parseRequest(r *http.Request, rules Rules) map[string]interface{} {
+// hasBody reports weather r has a body to parse.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// parseRequest parses request body and url params into one map.
+// If namespaces are enabled, values can also be parsed separately by where they come from using namespaced rule
+// names: header.X-Request-ID, cookie.session, path.id, query.page and body.email.
+// Values that are not JSON are converted by their rules (see convertReqVal) unless lossless option is set,
+// values that can't be converted are returned in Errors.
+func parseRequest(r *http.Request, rules Rules, c *config) (map[string]interface{}, Errors) {
 	m := make(map[string]interface{})
+	errs := make(Errors)
+	convert := func(m map[string]interface{}, rules Rules, parPath Path) {
+		if !c.lossless {
+			convertReqVals(m, rules, parPath, errs)
+		}
+	}
 
-	// parse request body by content type
+	// body and url params are parsed by un-namespaced rules and body namespace rules
+	namespaces := c.activeNamespaces()
+	nsRules := make(map[string]Rules, len(namespaces))
+	for _, ns := range namespaces {
+		nsRules[ns] = namespaceRules(rules, ns)
+	}
+	flatRules := make(Rules, len(rules))
+	for k, v := range rules {
+		if !isNamespaced(k, namespaces) {
+			flatRules[k] = v
+		}
+	}
+	for k, v := range nsRules["body"] {
+		if _, ok := flatRules[k]; !ok {
+			flatRules[k] = v
+		}
+	}
+
+	// url params are counted before they're parsed
+	if err := c.limits.checkFormFields(formFields([]byte(r.URL.RawQuery))); err != nil {
+		panic(newRequestError(err))
+	}
+
+	// parse request body by media type
 	contentType := r.Header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if contentType != "" && c.contentTypes != nil && (err != nil || !isMediaTypeAllowed(mediaType, c.contentTypes)) {
+		panic(newRequestError(fmt.Errorf("%w: %v", ErrUnsupportedMediaType, contentType)))
+	}
+	if contentType == "" && c.contentTypes != nil && hasBody(r) {
+		panic(newRequestError(fmt.Errorf("%w: missing Content-Type", ErrUnsupportedMediaType)))
+	}
+	if c.limits.MaxBodyBytes > 0 && hasBody(r) {
+		if err := c.limits.checkBodySize(r.ContentLength); err != nil {
+			panic(newRequestError(err))
+		}
+		r.Body = http.MaxBytesReader(nil, r.Body, c.limits.MaxBodyBytes)
+	}
 	switch {
-	case contentType == "application/json":
-		parseJSON(r, m)
-	case strings.Contains(contentType, "multipart/form-data"):
-		parseFormData(r, rules, m)
-	case contentType == "application/x-www-form-urlencoded":
-		parseURLEncoded(r, rules, m)
+	case err != nil:
+		// unparsable content types are ignored unless content types are restricted
+	case isJSONMediaType(mediaType):
+		root := parseJSON(r, flatRules, m, params["charset"], c)
+		if err := Validate(RootName, root, flatRules[RootName]); err != nil {
+			errs[RootName] = err.Error()
+		}
+		checkSource(m, "", "body", flatRules, false, errs)
+	case isXMLMediaType(mediaType):
+		parseXML(r, flatRules, m, params["charset"], c.limits)
+		convert(m, flatRules, nil)
+		checkSource(m, "", "body", flatRules, c.strictSources, errs)
+	case mediaType == "multipart/form-data":
+		parseFormData(r, flatRules, m, c.limits)
+		convert(m, flatRules, nil)
+		checkSource(m, "", "body", flatRules, c.strictSources, errs)
+	case mediaType == "application/x-www-form-urlencoded":
+		parseURLEncoded(r, flatRules, m, params["charset"], c.limits)
+		convert(m, flatRules, nil)
+		checkSource(m, "", "body", flatRules, c.strictSources, errs)
+	}
+
+	if nsRules["body"] != nil {
+		body := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			body[k] = v
+		}
+		defer func() {
+			setNamespace(m, "body", body, errs)
+		}()
 	}
 
 	// parse request url params
-	parseURLParams(r, rules, m)
+	if c.mergePolicy != MergeSeparate {
+		q, err := decodeParams(r.URL.Query(), flatRules, c.limits)
+		if err != nil {
+			panic(newRequestError(err))
+		}
+		convert(q, flatRules, nil)
+		checkSource(q, "", "query", flatRules, c.strictSources, errs)
+		policy := c.mergePolicy
+		if c.strictSources {
+			policy = MergeReject
+		}
+		for _, name := range mergeReqVals(m, q, policy) {
+			if policy == MergeReject {
+				errs[name] = fmt.Sprintf("%v must not be in both body and url params", name)
+			}
+		}
+	}
+
+	parsers := map[string]func(*http.Request, Rules, map[string]interface{}){
+		"header": parseHeaders,
+		"cookie": parseCookies,
+		"path":   parsePathValues,
+		"query": func(r *http.Request, rules Rules, m map[string]interface{}) {
+			parseURLParams(r, rules, m, c.limits)
+		},
+	}
+	for ns, parse := range parsers {
+		if nsRules[ns] != nil {
+			nsMap := make(map[string]interface{})
+			parse(r, nsRules[ns], nsMap)
+			convert(nsMap, nsRules[ns], Path{ns})
+			checkSource(nsMap, ns, ns, nsRules[ns], c.strictSources, errs)
+			setNamespace(m, ns, nsMap, errs)
+		}
+	}
+
+	return m, errs
+}
 
-	return m
+// setNamespace sets the values of namespace ns of m, a body or url value named ns is replaced and reported in errs.
+func setNamespace(m map[string]interface{}, ns string, values map[string]interface{}, errs Errors) {
+	if _, ok := m[ns]; ok {
+		errs[ns] = fmt.Sprintf("%v is reserved for %v values", ns, ns)
+	}
+	m[ns] = values
 }
@@ -1,14 +1,19 @@
 package valdn
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
+	"math"
+	"math/big"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func formDataRequest() *http.Request {
@@ -154,6 +159,36 @@ func multipleParamsURLEncodedRequest() *http.Request {
 	return r
 }
 
+func nestedURLEncodedRequest() *http.Request {
+	body := "address.city=cairo&address[zip]=11511&items.0.qty=1&items[1][qty]=2&tags[]=a&tags[]=b&other.x=y"
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func nestedFormDataRequest() *http.Request {
+	postData :=
+		`--xxx
+Content-Disposition: form-data; name="address.city"
+
+cairo
+--xxx
+Content-Disposition: form-data; name="docs[0][name]"
+
+passport
+--xxx
+Content-Disposition: form-data; name="docs[0][file]"; filename="passport.pdf"
+Content-Type: application/pdf
+
+binary data
+--xxx--
+`
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(postData)))
+	req.Header = http.Header{"Content-Type": {`multipart/form-data; boundary=xxx`}}
+
+	return req
+}
+
 func emptyURLEncodedRequest() *http.Request {
 	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(""))
 	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -167,6 +202,13 @@ func jsonRequest() *http.Request {
 	return r
 }
 
+func arrayJSONRequest() *http.Request {
+	jsonData := `[{"name":"Narmer"},{"name":"Hor-Aha","age":30}]`
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonData))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
 func advancedJSONRequest() *http.Request {
 	jsonData := `
 	{"val": {"numbers": [11, 11.1]}}
@@ -188,54 +230,648 @@ func paramsRequest() *http.Request {
 	return r
 }
 
+func contentTypeRequest(body string, contentType string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
 func brokenRequest() *http.Request {
 	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(""))
 	r.Header = http.Header{"Content-Type": {"text/plain; boundary="}}
 	return r
 }
 
-func Test_parseReqVal(t *testing.T) {
-	type args struct {
-		v string
+func Test_decodeCharset(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		charset string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "test decodeCharset without charset",
+			b:       []byte("caf\xc3\xa9"),
+			charset: "",
+			want:    "café",
+		},
+		{
+			name:    "test decodeCharset with utf-8",
+			b:       []byte("caf\xc3\xa9"),
+			charset: "UTF-8",
+			want:    "café",
+		},
+		{
+			name:    "test decodeCharset with iso-8859-1",
+			b:       []byte("caf\xe9"),
+			charset: "iso-8859-1",
+			want:    "café",
+		},
+		{
+			name:    "test decodeCharset with unknown charset",
+			b:       []byte("cafe"),
+			charset: "bla",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCharset(tt.b, tt.charset)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeCharset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("decodeCharset() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_isJSONMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      bool
+	}{
+		{mediaType: "application/json", want: true},
+		{mediaType: "application/vnd.api+json", want: true},
+		{mediaType: "application/merge-patch+json", want: true},
+		{mediaType: "application/xml", want: false},
+		{mediaType: "text/plain", want: false},
+	}
+	for _, tt := range tests {
+		t.Run("test isJSONMediaType with "+tt.mediaType, func(t *testing.T) {
+			if got := isJSONMediaType(tt.mediaType); got != tt.want {
+				t.Errorf("isJSONMediaType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isMediaTypeAllowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		allowed   []string
+		want      bool
+	}{
+		{
+			name:      "test isMediaTypeAllowed with allowed media type",
+			mediaType: "application/json",
+			allowed:   []string{"application/json"},
+			want:      true,
+		},
+		{
+			name:      "test isMediaTypeAllowed with allowed suffix",
+			mediaType: "application/vnd.api+json",
+			allowed:   []string{"", "+json"},
+			want:      true,
+		},
+		{
+			name:      "test isMediaTypeAllowed with not allowed media type",
+			mediaType: "text/plain",
+			allowed:   []string{"application/json", "+json"},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMediaTypeAllowed(tt.mediaType, tt.allowed); got != tt.want {
+				t.Errorf("isMediaTypeAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_convertToKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     string
+		kind    string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "test convertToKind with string", val: "01234", kind: "string", want: "01234"},
+		{name: "test convertToKind with bool", val: "true", kind: "bool", want: true},
+		{name: "test convertToKind with int", val: "-55", kind: "int", want: -55},
+		{name: "test convertToKind with int8", val: "55", kind: "int8", want: int8(55)},
+		{name: "test convertToKind with out of range int8", val: "555", kind: "int8", wantErr: true},
+		{name: "test convertToKind with uint16", val: "55", kind: "uint16", want: uint16(55)},
+		{name: "test convertToKind with negative uint", val: "-55", kind: "uint", wantErr: true},
+		{name: "test convertToKind with float32", val: "5.5", kind: "float32", want: float32(5.5)},
+		{name: "test convertToKind with unsuitable value", val: "bla", kind: "float64", wantErr: true},
+		{name: "test convertToKind with unknown kind", val: "bla", kind: "struct", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertToKind(tt.val, tt.kind)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("convertToKind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want && !tt.wantErr {
+				t.Errorf("convertToKind() = %v %T, want %v %T", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_convertReqVal(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     string
+		rules   []string
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name:  "test convertReqVal with float",
+			val:   "55.2",
+			rules: []string{"required", "float"},
+			want:  55.2,
+		},
+		{
+			name:  "test convertReqVal with integer",
+			val:   "55",
+			rules: []string{"int"},
+			want:  int64(55),
+		},
+		{
+			name:  "test convertReqVal with unsigned integer",
+			val:   "55",
+			rules: []string{"uint"},
+			want:  uint64(55),
+		},
+		{
+			name:  "test convertReqVal with complex",
+			val:   "19+73i",
+			rules: []string{"complex"},
+			want:  19 + 73i,
+		},
+		{
+			name:  "test convertReqVal with numeric integer",
+			val:   "1000",
+			rules: []string{"min:18"},
+			want:  int64(1000),
+		},
+		{
+			name:  "test convertReqVal with numeric float",
+			val:   "1e3",
+			rules: []string{"between:1,5000"},
+			want:  1000.0,
+		},
+		{
+			name:  "test convertReqVal with bool",
+			val:   "true",
+			rules: []string{"bool"},
+			want:  true,
+		},
+		{
+			name:  "test convertReqVal with kind",
+			val:   "55",
+			rules: []string{"kind:int8"},
+			want:  int8(55),
+		},
+		{
+			name:  "test convertReqVal with time",
+			val:   "2022-01-02",
+			rules: []string{"time", "timeFormat:2006-01-02"},
+			want:  time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "test convertReqVal with string rules",
+			val:   "01234",
+			rules: []string{"required", "len:5", "kind:slice"},
+			want:  "01234",
+		},
+		{
+			name:  "test convertReqVal without rules",
+			val:   "1+2i",
+			rules: nil,
+			want:  "1+2i",
+		},
+		{
+			name:    "test convertReqVal with non-integer value",
+			val:     "1e3",
+			rules:   []string{"int"},
+			wantErr: "zip must be an integer",
+		},
+		{
+			name:    "test convertReqVal with non-numeric value",
+			val:     "bla",
+			rules:   []string{"min:1"},
+			wantErr: "zip must be a numeric",
+		},
+		{
+			name:    "test convertReqVal with non-bool value",
+			val:     "yes",
+			rules:   []string{"kind:bool"},
+			wantErr: "zip must be kind of bool",
+		},
+		{
+			name:    "test convertReqVal with non-time value",
+			val:     "2022-01-02",
+			rules:   []string{"time"},
+			wantErr: "zip must be type of time.Time",
+		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertReqVal("zip", tt.val, tt.rules)
+			if err != nil && err.Error() != tt.wantErr || err == nil && tt.wantErr != "" {
+				t.Errorf("convertReqVal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertReqVal() = %v %T, want %v %T", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_convertReqVals(t *testing.T) {
+	m := map[string]interface{}{
+		"zip":  "01234",
+		"age":  "bla",
+		"ids":  []interface{}{"1", "2"},
+		"user": map[string]interface{}{"admin": "false"},
+	}
+	rules := Rules{"zip": {"len:5"}, "age": {"int"}, "ids.*": {"int"}, "user.admin": {"bool"}}
+	errs := make(Errors)
+	convertReqVals(m, rules, Path{"query"}, errs)
+	want := map[string]interface{}{
+		"zip":  "01234",
+		"age":  "bla",
+		"ids":  []interface{}{int64(1), int64(2)},
+		"user": map[string]interface{}{"admin": false},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("convertReqVals() = %v, want %v", m, want)
+	}
+	wantErrs := Errors{"query.age": "query.age must be an integer"}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("convertReqVals() errors = %v, want %v", errs, wantErrs)
+	}
+}
+
+func Test_mergeReqVals(t *testing.T) {
+	tests := []struct {
+		name           string
+		dst            map[string]interface{}
+		src            map[string]interface{}
+		policy         MergePolicy
+		want           map[string]interface{}
+		wantDuplicates []string
+	}{
+		{
+			name:   "test mergeReqVals with new value",
+			dst:    map[string]interface{}{"lang": "go"},
+			src:    map[string]interface{}{"page": "2"},
+			policy: MergeAppend,
+			want:   map[string]interface{}{"lang": "go", "page": "2"},
+		},
+		{
+			name:           "test mergeReqVals with existing value",
+			dst:            map[string]interface{}{"lang": 5},
+			src:            map[string]interface{}{"lang": []interface{}{"go", "js"}},
+			policy:         MergeAppend,
+			want:           map[string]interface{}{"lang": []interface{}{5, "go", "js"}},
+			wantDuplicates: []string{"lang"},
+		},
+		{
+			name:           "test mergeReqVals with existing slice",
+			dst:            map[string]interface{}{"lang": []interface{}{"go", "js"}},
+			src:            map[string]interface{}{"lang": "python"},
+			policy:         MergeAppend,
+			want:           map[string]interface{}{"lang": []interface{}{"go", "js", "python"}},
+			wantDuplicates: []string{"lang"},
+		},
+		{
+			name:           "test mergeReqVals with body wins policy",
+			dst:            map[string]interface{}{"role": "user"},
+			src:            map[string]interface{}{"role": "admin", "page": "2"},
+			policy:         MergeBodyWins,
+			want:           map[string]interface{}{"role": "user", "page": "2"},
+			wantDuplicates: []string{"role"},
+		},
+		{
+			name:           "test mergeReqVals with query wins policy",
+			dst:            map[string]interface{}{"role": "user"},
+			src:            map[string]interface{}{"role": "admin"},
+			policy:         MergeQueryWins,
+			want:           map[string]interface{}{"role": "admin"},
+			wantDuplicates: []string{"role"},
+		},
+		{
+			name:           "test mergeReqVals with reject policy",
+			dst:            map[string]interface{}{"role": "user"},
+			src:            map[string]interface{}{"role": "admin"},
+			policy:         MergeReject,
+			want:           map[string]interface{}{"role": "user"},
+			wantDuplicates: []string{"role"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duplicates := mergeReqVals(tt.dst, tt.src, tt.policy)
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("mergeReqVals() = %v, want %v", tt.dst, tt.want)
+			}
+			if !reflect.DeepEqual(duplicates, tt.wantDuplicates) {
+				t.Errorf("mergeReqVals() duplicates = %v, want %v", duplicates, tt.wantDuplicates)
+			}
+		})
+	}
+}
+
+func Test_ruleSources(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+		want  []string
+	}{
+		{name: "test ruleSources", rules: []string{"required", "source:body,query"}, want: []string{"body", "query"}},
+		{name: "test ruleSources without source rule", rules: []string{"required"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleSources(tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ruleSources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_expectsMany(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		want  bool
+	}{
+		{name: "test expectsMany with kind", rules: Rules{"tags": {"kind:slice"}}, want: true},
+		{name: "test expectsMany with kindIn", rules: Rules{"tags": {"kindIn:array,slice"}}, want: true},
+		{name: "test expectsMany with nested rules", rules: Rules{"tags.*": {"required"}}, want: true},
+		{name: "test expectsMany with scalar rules", rules: Rules{"tags": {"required", "kind:string"}, "tagsCount": {"int"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expectsMany(tt.rules, "tags"); got != tt.want {
+				t.Errorf("expectsMany() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkSource(t *testing.T) {
+	rules := Rules{"role": {"required", "source:body"}, "page": {"int", "source:query"}, "tags": {"kind:slice"}, "lang": {"required"},
+		"user.role": {"source:body"}, "items": {"kind:slice"}, "ids.*": {"source:query"}}
+	tests := []struct {
+		name     string
+		src      map[string]interface{}
+		source   string
+		strict   bool
+		want     map[string]interface{}
+		wantErrs Errors
+	}{
+		{
+			name:     "test checkSource",
+			src:      map[string]interface{}{"role": "admin", "page": "2", "lang": []interface{}{"go", "js"}},
+			source:   "query",
+			strict:   false,
+			want:     map[string]interface{}{"page": "2", "lang": []interface{}{"go", "js"}},
+			wantErrs: Errors{"role": "role must be sent in body"},
+		},
+		{
+			name:     "test checkSource in strict mode",
+			src:      map[string]interface{}{"role": "admin", "tags": []interface{}{"a", "b"}, "lang": []interface{}{"go", "js"}},
+			source:   "body",
+			strict:   true,
+			want:     map[string]interface{}{"role": "admin", "tags": []interface{}{"a", "b"}},
+			wantErrs: Errors{"lang": "lang must have one value"},
+		},
+		{
+			name:     "test checkSource with nested values",
+			src:      map[string]interface{}{"user": map[string]interface{}{"role": "admin", "tags": []interface{}{"a", "b"}}, "lang": "go"},
+			source:   "query",
+			strict:   true,
+			want:     map[string]interface{}{"user": map[string]interface{}{}, "lang": "go"},
+			wantErrs: Errors{"user.role": "user.role must be sent in body", "user.tags": "user.tags must have one value"},
+		},
+		{
+			name:     "test checkSource with items",
+			src:      map[string]interface{}{"items": []interface{}{map[string]interface{}{"sku": []interface{}{"a", "b"}}}},
+			source:   "query",
+			strict:   true,
+			want:     map[string]interface{}{"items": []interface{}{map[string]interface{}{}}},
+			wantErrs: Errors{"items.0.sku": "items.0.sku must have one value"},
+		},
+		{
+			name:     "test checkSource with wildcard rules",
+			src:      map[string]interface{}{"ids": []interface{}{"1", "2"}},
+			source:   "body",
+			strict:   false,
+			want:     map[string]interface{}{},
+			wantErrs: Errors{"ids.0": "ids.0 must be sent in query"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := make(Errors)
+			checkSource(tt.src, "", tt.source, rules, tt.strict, errs)
+			if !reflect.DeepEqual(tt.src, tt.want) {
+				t.Errorf("checkSource() = %v, want %v", tt.src, tt.want)
+			}
+			if !reflect.DeepEqual(errs, tt.wantErrs) {
+				t.Errorf("checkSource() errors = %v, want %v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func Test_splitParamKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{key: "lang", want: []string{"lang"}},
+		{key: "tags[]", want: []string{"tags", ""}},
+		{key: "filter[status]", want: []string{"filter", "status"}},
+		{key: "items[0][sku]", want: []string{"items", "0", "sku"}},
+		{key: "address.city", want: []string{"address", "city"}},
+		{key: "items.0.qty", want: []string{"items", "0", "qty"}},
+		{key: "items[0].qty", want: []string{"items", "0", "qty"}},
+		{key: "filter[a.b]", want: []string{"filter", "a.b"}},
+		{key: "items[0]sku]", want: []string{"items[0]sku]"}},
+		{key: "items[0", want: []string{"items[0"}},
+		{key: "[0]", want: []string{"[0]"}},
+	}
+	for _, tt := range tests {
+		t.Run("test splitParamKey with "+tt.key, func(t *testing.T) {
+			if got := splitParamKey(tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitParamKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_listifyParams(t *testing.T) {
 	tests := []struct {
 		name string
-		args args
+		val  interface{}
 		want interface{}
 	}{
 		{
-			name: "test parseReqVal with float",
-			args: args{
-				v: "55.2",
-			},
-			want: 55.2,
+			name: "test listifyParams with indexes",
+			val:  map[string]interface{}{"1": "b", "0": map[string]interface{}{"0": "a"}},
+			want: []interface{}{[]interface{}{"a"}, "b"},
 		},
 		{
-			name: "test parseReqVal with integer",
-			args: args{
-				v: "55",
-			},
-			want: int64(55),
+			name: "test listifyParams with sparse indexes",
+			val:  map[string]interface{}{"0": "a", "5": "b"},
+			want: map[string]interface{}{"0": "a", "5": "b"},
 		},
 		{
-			name: "test parseReqVal with complex",
-			args: args{
-				v: "19+73i",
+			name: "test listifyParams with names",
+			val:  map[string]interface{}{"status": "open", "01": "a"},
+			want: map[string]interface{}{"status": "open", "01": "a"},
+		},
+		{
+			name: "test listifyParams with value",
+			val:  "a",
+			want: "a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listifyParams(tt.val); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listifyParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeParams(t *testing.T) {
+	params := map[string][]string{
+		"lang":           {"go"},
+		"tags[]":         {"a", "b"},
+		"filter[status]": {"open"},
+		"items[0][sku]":  {"x"},
+		"items[1][sku]":  {"y"},
+		"items[1][qty]":  {"2"},
+		"page":           {"2"},
+	}
+	tests := []struct {
+		name    string
+		params  map[string][]string
+		rules   Rules
+		limits  Limits
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name:  "test decodeParams",
+			rules: Rules{"lang": {"required"}, "tags": {"required"}, "filter.status": {"in:open"}, "items.*.sku": {"required"}},
+			want: map[string]interface{}{
+				"lang":   "go",
+				"tags":   []interface{}{"a", "b"},
+				"filter": map[string]interface{}{"status": "open"},
+				"items": []interface{}{
+					map[string]interface{}{"sku": "x"},
+					map[string]interface{}{"sku": "y", "qty": "2"},
+				},
 			},
-			want: 19 + 73i,
 		},
 		{
-			name: "test parseReqVal with non-integer, non-float and non-complex value",
-			args: args{
-				v: "bla",
+			name:  "test decodeParams with root rule",
+			rules: Rules{".*": {"required"}, "tags[]": {"required"}},
+			want: map[string]interface{}{
+				"lang":   "go",
+				"page":   "2",
+				"tags":   []interface{}{"a", "b"},
+				"filter": map[string]interface{}{"status": "open"},
+				"items": []interface{}{
+					map[string]interface{}{"sku": "x"},
+					map[string]interface{}{"sku": "y", "qty": "2"},
+				},
 			},
-			want: "bla",
+		},
+		{
+			name:  "test decodeParams with empty rules",
+			rules: Rules{},
+			want:  map[string]interface{}{},
+		},
+		{
+			name:    "test decodeParams with too deep param",
+			rules:   Rules{"items": {"required"}},
+			limits:  Limits{MaxDepth: 2},
+			wantErr: ErrTooDeep,
+		},
+		{
+			name:   "test decodeParams with too deep param of other rules",
+			rules:  Rules{"lang": {"required"}},
+			limits: Limits{MaxDepth: 2},
+			want:   map[string]interface{}{"lang": "go"},
+		},
+		{
+			name:   "test decodeParams with param of max default depth",
+			params: map[string][]string{"a" + strings.Repeat("[x]", maxParamDepth-1): {"1"}},
+			rules:  Rules{"a": {"required"}},
+			want:   map[string]interface{}{"a": nestedParam(maxParamDepth-1, "1")},
+		},
+		{
+			name:    "test decodeParams with too deep param without limits",
+			params:  map[string][]string{"a" + strings.Repeat("[x]", maxParamDepth): {"1"}},
+			rules:   Rules{"a": {"required"}},
+			wantErr: ErrTooDeep,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := parseReqVal(tt.args.v); got != tt.want {
-				t.Errorf("parseReqVal() = %v %T, want %v %T", got, got, tt.want, tt.want)
+			p := params
+			if tt.params != nil {
+				p = tt.params
+			}
+			got, err := decodeParams(p, tt.rules, tt.limits)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("decodeParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// nestedParam returns val nested in depth maps of key x.
+func nestedParam(depth int, val interface{}) interface{} {
+	for i := 0; i < depth; i++ {
+		val = map[string]interface{}{"x": val}
+	}
+	return val
+}
+
+func Test_parseJSONNumber(t *testing.T) {
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	bigRat, _ := new(big.Rat).SetString("0.1000000000000000000001")
+	tests := []struct {
+		n    json.Number
+		want interface{}
+	}{
+		{n: "11", want: 11},
+		{n: "-11", want: -11},
+		{n: "9007199254740993", want: 9007199254740993},
+		{n: "123456789012345678901234567890", want: bigInt},
+		{n: "1.0", want: 1.0},
+		{n: "11.1", want: 11.1},
+		{n: "0.1", want: 0.1},
+		{n: "1e21", want: 1e21},
+		{n: "0.1000000000000000000001", want: bigRat},
+		{n: "1e99999", want: math.Inf(1)},
+	}
+	for _, tt := range tests {
+		t.Run("test parseJSONNumber with "+tt.n.String(), func(t *testing.T) {
+			if got := parseJSONNumber(tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseJSONNumber() = %#v, want %#v", got, tt.want)
 			}
 		})
 	}
@@ -247,8 +883,9 @@ func Test_parseJSONVal(t *testing.T) {
 		panic(err)
 	}
 	m := make(map[string]interface{})
-	err = json.Unmarshal(b, &m)
-	if err != nil {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(&m); err != nil {
 		panic(err)
 	}
 
@@ -326,6 +963,7 @@ func Test_parseJSON(t *testing.T) {
 		req       *http.Request
 		m         map[string]interface{}
 		want      map[string]interface{}
+		wantRoot  interface{}
 		wantPanic bool
 	}{
 		{
@@ -333,6 +971,7 @@ func Test_parseJSON(t *testing.T) {
 			req:       jsonRequest(),
 			m:         make(map[string]interface{}),
 			want:      map[string]interface{}{"lang": "go"},
+			wantRoot:  map[string]interface{}{"lang": "go"},
 			wantPanic: false,
 		},
 		{
@@ -340,6 +979,29 @@ func Test_parseJSON(t *testing.T) {
 			req:       advancedJSONRequest(),
 			m:         make(map[string]interface{}),
 			want:      map[string]interface{}{"val": map[string]interface{}{"numbers": []interface{}{11, 11.1}}},
+			wantRoot:  map[string]interface{}{"val": map[string]interface{}{"numbers": []interface{}{11, 11.1}}},
+			wantPanic: false,
+		},
+		{
+			name: "test parseJSON with array root",
+			req:  arrayJSONRequest(),
+			m:    make(map[string]interface{}),
+			want: map[string]interface{}{
+				"0": map[string]interface{}{"name": "Narmer"},
+				"1": map[string]interface{}{"name": "Hor-Aha", "age": 30},
+			},
+			wantRoot: []interface{}{
+				map[string]interface{}{"name": "Narmer"},
+				map[string]interface{}{"name": "Hor-Aha", "age": 30},
+			},
+			wantPanic: false,
+		},
+		{
+			name:      "test parseJSON with scalar root",
+			req:       httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`"Narmer"`)),
+			m:         make(map[string]interface{}),
+			want:      map[string]interface{}{},
+			wantRoot:  "Narmer",
 			wantPanic: false,
 		},
 		{
@@ -357,10 +1019,13 @@ func Test_parseJSON(t *testing.T) {
 					t.Errorf("parseJSON() panic = %v, wantPanic %v", e, tt.wantPanic)
 				}
 			}()
-			parseJSON(tt.req, tt.m)
+			root := parseJSON(tt.req, Rules{}, tt.m, "", newConfig(nil))
 			if !reflect.DeepEqual(tt.m, tt.want) {
 				t.Errorf("parseJSON() = %v, want %v", tt.m, tt.want)
 			}
+			if !reflect.DeepEqual(root, tt.wantRoot) {
+				t.Errorf("parseJSON() root = %v, want %v", root, tt.wantRoot)
+			}
 		})
 	}
 }
@@ -446,7 +1111,7 @@ func Test_parseFormData(t *testing.T) {
 					t.Errorf("parseFormData() panic = %v, wantPanic %v", e, tt.wantPanic)
 				}
 			}()
-			parseFormData(tt.req, tt.rules, tt.m)
+			parseFormData(tt.req, tt.rules, tt.m, Limits{})
 			if _, ok := tt.m["field"]; ok {
 				k := reflect.TypeOf(tt.m["field"]).Kind()
 				switch k {
@@ -476,6 +1141,26 @@ func Test_parseFormData(t *testing.T) {
 	}
 }
 
+func Test_parseFormData_nestedFields(t *testing.T) {
+	m := make(map[string]interface{})
+	parseFormData(nestedFormDataRequest(), Rules{"address.city": {"required"}, "docs": {"required"}}, m, Limits{})
+
+	if !reflect.DeepEqual(m["address"], map[string]interface{}{"city": "cairo"}) {
+		t.Errorf("parseFormData() address = %v, want map[city:cairo]", m["address"])
+	}
+	docs, ok := m["docs"].([]interface{})
+	if !ok || len(docs) != 1 {
+		t.Fatalf("parseFormData() docs = %v, want one item", m["docs"])
+	}
+	doc, _ := docs[0].(map[string]interface{})
+	if doc["name"] != "passport" {
+		t.Errorf("parseFormData() docs.0.name = %v, want passport", doc["name"])
+	}
+	if f, ok := doc["file"].(*multipart.FileHeader); !ok || f.Filename != "passport.pdf" {
+		t.Errorf("parseFormData() docs.0.file = %v, want passport.pdf", doc["file"])
+	}
+}
+
 func Test_parseURLEncoded(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -525,6 +1210,18 @@ func Test_parseURLEncoded(t *testing.T) {
 			wantPanic: false,
 			want:      map[string]interface{}{"lang": []interface{}{"go", "python"}},
 		},
+		{
+			name:      "test parseURLEncoded with nested fields",
+			rules:     Rules{"address.city": {"required"}, "items.0.qty": {"required"}, "tags": {"required"}},
+			req:       nestedURLEncodedRequest(),
+			m:         make(map[string]interface{}),
+			wantPanic: false,
+			want: map[string]interface{}{
+				"address": map[string]interface{}{"city": "cairo", "zip": "11511"},
+				"items":   []interface{}{map[string]interface{}{"qty": "1"}, map[string]interface{}{"qty": "2"}},
+				"tags":    []interface{}{"a", "b"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -533,7 +1230,7 @@ func Test_parseURLEncoded(t *testing.T) {
 					t.Errorf("parseURLEncoded() panic = %v, wantPanic %v", e, tt.wantPanic)
 				}
 			}()
-			parseURLEncoded(tt.req, tt.rules, tt.m)
+			parseURLEncoded(tt.req, tt.rules, tt.m, "", Limits{})
 			if !reflect.DeepEqual(tt.m, tt.want) {
 				t.Errorf("parseURLEncoded() = %v, want %v", tt.m, tt.want)
 			}
@@ -577,6 +1274,20 @@ func Test_parseURLParams(t *testing.T) {
 			m:     make(map[string]interface{}),
 			want:  map[string]interface{}{"lang": "go"},
 		},
+		{
+			name:  "test parseURLParams with missing param",
+			rules: Rules{"lang": {"required"}, "page": {"required"}, "size": {"required"}},
+			req:   httptest.NewRequest(http.MethodGet, "http://example.com/?size=10&lang=go", strings.NewReader("")),
+			m:     make(map[string]interface{}),
+			want:  map[string]interface{}{"lang": "go", "size": "10"},
+		},
+		{
+			name:  "test parseURLParams with bracket params",
+			rules: Rules{"filter.status": {"required"}},
+			req:   httptest.NewRequest(http.MethodGet, "http://example.com/?filter[status]=open", strings.NewReader("")),
+			m:     make(map[string]interface{}),
+			want:  map[string]interface{}{"filter": map[string]interface{}{"status": "open"}},
+		},
 		{
 			name:  "test parseURLParams with one value",
 			rules: Rules{"lang": {"required"}},
@@ -594,7 +1305,7 @@ func Test_parseURLParams(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parseURLParams(tt.req, tt.rules, tt.m)
+			parseURLParams(tt.req, tt.rules, tt.m, Limits{})
 			if !reflect.DeepEqual(tt.m, tt.want) {
 				t.Errorf("parseURLParams() = %v, want %v", tt.m, tt.want)
 			}
@@ -602,10 +1313,81 @@ func Test_parseURLParams(t *testing.T) {
 	}
 }
 
+func namespacesRequest() *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "http://example.com/users/5?page=2&lang=js", strings.NewReader(`{"lang":"go","email":"a@b.c"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Request-ID", "f47ac10b-58cc-0372-8567-0e02b2c3d479")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "application/json")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	r.SetPathValue("id", "5")
+	return r
+}
+
+func Test_namespaceRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     Rules
+		namespace string
+		want      Rules
+	}{
+		{
+			name:      "test namespaceRules",
+			rules:     Rules{"header.X-Request-ID": {"uuid"}, "header.Accept": {"required"}, "lang": {"required"}},
+			namespace: "header",
+			want:      Rules{"X-Request-ID": {"uuid"}, "Accept": {"required"}},
+		},
+		{
+			name:      "test namespaceRules without namespace rules",
+			rules:     Rules{"lang": {"required"}, "headers": {"required"}},
+			namespace: "header",
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namespaceRules(tt.rules, tt.namespace); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("namespaceRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseHeaders(t *testing.T) {
+	m := make(map[string]interface{})
+	parseHeaders(namespacesRequest(), Rules{"X-Request-ID": {"uuid"}, "accept": {"required"}, "Authorization": {"required"}}, m)
+	want := map[string]interface{}{
+		"X-Request-ID": "f47ac10b-58cc-0372-8567-0e02b2c3d479",
+		"accept":       []interface{}{"text/html", "application/json"},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("parseHeaders() = %v, want %v", m, want)
+	}
+}
+
+func Test_parseCookies(t *testing.T) {
+	m := make(map[string]interface{})
+	parseCookies(namespacesRequest(), Rules{"session": {"required"}, "token": {"required"}}, m)
+	want := map[string]interface{}{"session": "abc"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("parseCookies() = %v, want %v", m, want)
+	}
+}
+
+func Test_parsePathValues(t *testing.T) {
+	m := make(map[string]interface{})
+	parsePathValues(namespacesRequest(), Rules{"id": {"required"}, "slug": {"required"}}, m)
+	want := map[string]interface{}{"id": "5"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("parsePathValues() = %v, want %v", m, want)
+	}
+}
+
 func Test_parseRequest(t *testing.T) {
 	tests := []struct {
 		name      string
 		rules     Rules
+		opts      []Option
 		req       *http.Request
 		want      map[string]interface{}
 		wantPanic bool
@@ -638,10 +1420,128 @@ func Test_parseRequest(t *testing.T) {
 			want:      map[string]interface{}{"lang": "go"},
 			wantPanic: false,
 		},
+		{
+			name: "test parse with namespaces",
+			rules: Rules{
+				"header.X-Request-ID": {"uuid"},
+				"cookie.session":      {"required"},
+				"path.id":             {"required"},
+				"query.page":          {"required"},
+				"body.email":          {"email"},
+			},
+			opts: []Option{WithNamespaces()},
+			req:  namespacesRequest(),
+			want: map[string]interface{}{
+				"lang":   "go",
+				"email":  "a@b.c",
+				"header": map[string]interface{}{"X-Request-ID": "f47ac10b-58cc-0372-8567-0e02b2c3d479"},
+				"cookie": map[string]interface{}{"session": "abc"},
+				"path":   map[string]interface{}{"id": "5"},
+				"query":  map[string]interface{}{"page": "2"},
+				"body":   map[string]interface{}{"lang": "go", "email": "a@b.c"},
+			},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with body namespace and form",
+			rules:     Rules{"body.lang": {"required"}},
+			opts:      []Option{WithNamespaces()},
+			req:       urlencodedRequest(),
+			want:      map[string]interface{}{"lang": "go", "body": map[string]interface{}{"lang": "go"}},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with merge body wins policy",
+			rules:     Rules{"lang": {"required"}},
+			opts:      []Option{WithMergePolicy(MergeBodyWins)},
+			req:       namespacesRequest(),
+			want:      map[string]interface{}{"lang": "go", "email": "a@b.c"},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with merge separate policy",
+			rules:     Rules{"lang": {"required"}, "query.lang": {"required"}},
+			opts:      []Option{WithMergePolicy(MergeSeparate)},
+			req:       namespacesRequest(),
+			want:      map[string]interface{}{"lang": "go", "email": "a@b.c", "query": map[string]interface{}{"lang": "js"}},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with json and charset",
+			rules:     Rules{},
+			req:       contentTypeRequest(`{"lang":"go"}`, "application/json; charset=utf-8"),
+			want:      map[string]interface{}{"lang": "go"},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with json suffix",
+			rules:     Rules{},
+			req:       contentTypeRequest(`{"lang":"go"}`, "application/vnd.api+json"),
+			want:      map[string]interface{}{"lang": "go"},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with latin-1 application/x-www-form-urlencoded",
+			rules:     Rules{"lang": {"required"}},
+			req:       contentTypeRequest("lang=caf%E9", "application/x-www-form-urlencoded; charset=ISO-8859-1"),
+			want:      map[string]interface{}{"lang": "café"},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with unsupported charset",
+			rules:     Rules{},
+			req:       contentTypeRequest(`{"lang":"go"}`, "application/json; charset=bla"),
+			wantPanic: true,
+		},
+		{
+			name:      "test parse with unknown content type",
+			rules:     Rules{"lang": {"required"}},
+			req:       contentTypeRequest("lang=go", "text/plain"),
+			want:      map[string]interface{}{},
+			wantPanic: false,
+		},
+		{
+			name:      "test parse with not allowed content type",
+			rules:     Rules{"lang": {"required"}},
+			opts:      []Option{WithContentTypes("application/json")},
+			req:       contentTypeRequest("lang=go", "text/plain"),
+			wantPanic: true,
+		},
+		{
+			name:      "test parse with broken content type",
+			rules:     Rules{"lang": {"required"}},
+			opts:      []Option{WithContentTypes("application/json")},
+			req:       brokenRequest(),
+			wantPanic: true,
+		},
+		{
+			name:      "test parse with missing content type",
+			rules:     Rules{"lang": {"required"}},
+			opts:      []Option{WithContentTypes("application/json")},
+			req:       contentTypeRequest("lang=go", ""),
+			wantPanic: true,
+		},
+		{
+			name:      "test parse with allowed content type",
+			rules:     Rules{},
+			opts:      []Option{WithContentTypes("+json")},
+			req:       contentTypeRequest(`{"lang":"go"}`, "application/merge-patch+json"),
+			want:      map[string]interface{}{"lang": "go"},
+			wantPanic: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := parseRequest(tt.req, tt.rules)
+			defer func() {
+				e := recover()
+				if (e != nil) != tt.wantPanic {
+					t.Errorf("parseRequest() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+				if _, ok := e.(*RequestError); e != nil && !ok {
+					t.Errorf("parseRequest() panic = %T, want *RequestError", e)
+				}
+			}()
+			m, _ := parseRequest(tt.req, tt.rules, newConfig(tt.opts))
 			if !reflect.DeepEqual(m, tt.want) {
 				t.Errorf("getFieldRules() = %v %T, want %v %T", m, m["field"], tt.want, tt.want["field"])
 			}
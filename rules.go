@@ -19,10 +19,9 @@ type rule struct {
 var registeredRules = make(map[string]*rule)
 
 // AddRule registers a new rule.
-// It panics if the rule is already registered.
+// It panics if the rule (or an alias of its name) is already registered.
 func AddRule(name string, fn RuleFunc, errMsg string) {
-	_, ruleExist := registeredRules[name]
-	if ruleExist {
+	if isRegistered(name) {
 		panic("rule is already registered")
 	}
 	r := &rule{
@@ -42,9 +41,13 @@ func OverwriteRule(name string, fn RuleFunc, errMsg string) {
 	registeredRules[name] = r
 }
 
-// SetErrMsg sets errMsg to ruleName.
+// SetErrMsg sets errMsg to ruleName, ruleName may be an alias so the errors of its rules are replaced by errMsg.
 // It panics if rule does not exist.
 func SetErrMsg(ruleName string, errMsg string) {
+	if a, ok := registeredAliases[ruleName]; ok {
+		a.errMsg = errMsg
+		return
+	}
 	r, ok := registeredRules[ruleName]
 	if !ok {
 		panic("cannot set error message to rule does not exist: " + ruleName)
@@ -53,7 +56,12 @@ func SetErrMsg(ruleName string, errMsg string) {
 }
 
 func GetErrMsg(ruleName string, ruleVal string, name string, val interface{}) string {
-	errMsg := registeredRules[ruleName].errMsg
+	var errMsg string
+	if a, ok := registeredAliases[ruleName]; ok {
+		errMsg = a.errMsg
+	} else {
+		errMsg = registeredRules[ruleName].errMsg
+	}
 	errMsg = strings.ReplaceAll(errMsg, "[name]", name)
 	errMsg = strings.ReplaceAll(errMsg, "[val]", toString(val))
 	errMsg = strings.ReplaceAll(errMsg, "[ruleVal]", ruleVal)
@@ -205,6 +213,15 @@ func ufloatRule(name string, val interface{}, ruleVal string) error {
 	return nil
 }
 
+// boolRule checks if val is boolean.
+// It returns error if val is not a boolean.
+func boolRule(name string, val interface{}, ruleVal string) error {
+	if !IsBool(val) {
+		return errors.New(GetErrMsg("bool", ruleVal, name, val))
+	}
+	return nil
+}
+
 // numericRule checks if val is numeric.
 // It returns error if val is not a numeric.
 func numericRule(name string, val interface{}, ruleVal string) error {
@@ -221,8 +238,7 @@ func numericRule(name string, val interface{}, ruleVal string) error {
 // It panics if max is not an integer or a float.
 // It returns error if val is not between min and max.
 func betweenRule(name string, val interface{}, ruleVal string) error {
-	vFloat, err := interfaceToFloat(val)
-	if err != nil {
+	if !IsInteger(val) && !IsFloat(val) {
 		panic(name + " must be an integer or a float to be validated by betweenRule")
 	}
 
@@ -230,16 +246,18 @@ func betweenRule(name string, val interface{}, ruleVal string) error {
 	if len(ruleValSpliced) != 2 {
 		panic(fmt.Errorf("betweenRule expects two numeric values as min and max, got: %v", len(ruleValSpliced)))
 	}
-	min, err := stringToFloat(ruleValSpliced[0])
+	min, err := stringToRat(ruleValSpliced[0])
 	if err != nil {
 		panic(fmt.Errorf("betweenRule: min must be an integer or a float, got: %v", ruleValSpliced[0]))
 	}
-	max, err := stringToFloat(ruleValSpliced[1])
+	max, err := stringToRat(ruleValSpliced[1])
 	if err != nil {
 		panic(fmt.Errorf("betweenRule: max must be an integer or a float, got: %v", ruleValSpliced[1]))
 	}
 
-	if vFloat < min || vFloat > max {
+	minCmp, _ := compareNumber(val, min)
+	maxCmp, _ := compareNumber(val, max)
+	if minCmp < 0 || maxCmp > 0 {
 		return errors.New(GetErrMsg("between", ruleVal, name, val))
 	}
 	return nil
@@ -251,16 +269,15 @@ func betweenRule(name string, val interface{}, ruleVal string) error {
 // It panics if ruleVal is not an integer or a float.
 // It returns error if val is lower than ruleVal.
 func minRule(name string, val interface{}, ruleVal string) error {
-	vFloat, err := interfaceToFloat(val)
-	if err != nil {
+	if !IsInteger(val) && !IsFloat(val) {
 		panic(name + " must be an integer or a float to be validated by minRule")
 	}
-	min, err := stringToFloat(ruleVal)
+	min, err := stringToRat(ruleVal)
 	if err != nil {
 		panic(fmt.Errorf("minRule: min must be an integer or a float, got: %v", ruleVal))
 	}
 
-	if vFloat < min {
+	if cmp, _ := compareNumber(val, min); cmp < 0 {
 		return errors.New(GetErrMsg("min", ruleVal, name, val))
 	}
 	return nil
@@ -272,16 +289,15 @@ func minRule(name string, val interface{}, ruleVal string) error {
 // It panics if ruleVal is not an integer or a float.
 // It returns error if val is greater than ruleVal.
 func maxRule(name string, val interface{}, ruleVal string) error {
-	vFloat, err := interfaceToFloat(val)
-	if err != nil {
+	if !IsInteger(val) && !IsFloat(val) {
 		panic(name + " must be an integer or a float to be validated by minRule")
 	}
-	max, err := stringToFloat(ruleVal)
+	max, err := stringToRat(ruleVal)
 	if err != nil {
 		panic(fmt.Errorf("maxRule: max must be an integer or a float, got: %v", ruleVal))
 	}
 
-	if vFloat > max {
+	if cmp, _ := compareNumber(val, max); cmp > 0 {
 		return errors.New(GetErrMsg("max", ruleVal, name, val))
 	}
 	return nil
@@ -608,8 +624,12 @@ func timeRule(name string, val interface{}, ruleVal string) error {
 }
 
 // timeFormatRule checks if val's format matches ruleVal.
+// Values of type time.Time are already parsed, so they match any format.
 // It returns error if val's format doesn't match ruleVal.
 func timeFormatRule(name string, val interface{}, ruleVal string) error {
+	if _, ok := val.(time.Time); ok {
+		return nil
+	}
 	_, err := time.Parse(ruleVal, toString(val))
 	if err != nil {
 		return errors.New(GetErrMsg("timeFormat", ruleVal, name, val))
@@ -836,6 +856,37 @@ func extNotInRule(name string, val interface{}, ruleVal string) error {
 	return nil
 }
 
+// mimeRule checks if val's media type matches ruleVal, e.g. image/png or image/*.
+// The media type of multipart.FileHeader is its declared Content-Type, and of os.File is guessed by its extension.
+// It panics if val is not a valid file.
+// It returns error if val's media type doesn't match ruleVal.
+func mimeRule(name string, val interface{}, ruleVal string) error {
+	mediaType, err := getFileMIME(val)
+	if err != nil {
+		panic(err)
+	}
+	if !isMIMEMatch(mediaType, ruleVal) {
+		return errors.New(GetErrMsg("mime", ruleVal, name, val))
+	}
+	return nil
+}
+
+// mimeInRule checks if val's media type matches one of ruleVal[] items.
+// It panics if val is not a valid file.
+// It returns error if val's media type doesn't match any item in ruleVal[].
+func mimeInRule(name string, val interface{}, ruleVal string) error {
+	mediaType, err := getFileMIME(val)
+	if err != nil {
+		panic(err)
+	}
+	for _, v := range strings.Split(ruleVal, ",") {
+		if isMIMEMatch(mediaType, v) {
+			return nil
+		}
+	}
+	return errors.New(GetErrMsg("mimeIn", ruleVal, name, val))
+}
+
 // uuidRule checks if val is a valid UUID.
 // It panics if val is not a string.
 // It returns error if val is not a valid UUID.
@@ -872,6 +923,13 @@ func phoneNumberRule(name string, val interface{}, ruleVal string) error {
 	return nil
 }
 
+// sourceRule declares where a request value may come from (body, query, header, cookie or path), it's checked by
+// ValidateRequest.
+// It always returns nil when used to validate val.
+func sourceRule(name string, val interface{}, ruleVal string) error {
+	return nil
+}
+
 func init() {
 	AddRule("required", requiredRule, "[name] is required")
 	AddRule("type", typeRule, "[name] must be type of [ruleVal]")
@@ -889,6 +947,7 @@ func init() {
 	AddRule("float", floatRule, "[name] must be a float")
 	AddRule("ufloat", ufloatRule, "[name] must be an unsigned float")
 	AddRule("numeric", numericRule, "[name] must be a numeric")
+	AddRule("bool", boolRule, "[name] must be a boolean")
 	AddRule("between", betweenRule, "[name] must be between [ruleVal]")
 	AddRule("min", minRule, "[name] must be greater than or equal [ruleVal]")
 	AddRule("max", maxRule, "[name] must be lower than or equal [ruleVal]")
@@ -922,6 +981,9 @@ func init() {
 	AddRule("notExt", notExtRule, "[name]'s extension must not be [ruleVal]")
 	AddRule("extIn", extInRule, "[name]'s extension must be one of [ruleVal]")
 	AddRule("extNotIn", extNotInRule, "[name]'s extension must not be one of [ruleVal]")
+	AddRule("mime", mimeRule, "[name]'s media type must be [ruleVal]")
+	AddRule("mimeIn", mimeInRule, "[name]'s media type must be one of [ruleVal]")
 	AddRule("uuid", uuidRule, "[name] must be a valid uuid")
 	AddRule("phoneNumber", phoneNumberRule, "[name] must be a valid phone number")
+	AddRule("source", sourceRule, "[name] must be sent in [ruleVal]")
 }
@@ -1,6 +1,8 @@
 package valdn
 
 import (
+	"math"
+	"math/big"
 	"mime/multipart"
 	"net/textproto"
 	"os"
@@ -40,6 +42,16 @@ func Test_AddRule(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "test add rule of alias name",
+			args: args{
+				name: "test_username",
+				f: func(name string, fVal interface{}, rVal string) error {
+					return nil
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1163,6 +1175,45 @@ func Test_ufloatRule(t *testing.T) {
 	}
 }
 
+func Test_boolRule(t *testing.T) {
+	type args struct {
+		name    string
+		val     interface{}
+		ruleVal string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "test bool rule",
+			args: args{
+				name:    "active",
+				val:     false,
+				ruleVal: "",
+			},
+			wantErr: false,
+		},
+		{
+			name: "test bool rule with unsuitable data",
+			args: args{
+				name:    "active",
+				val:     "true",
+				ruleVal: "",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := boolRule(tt.args.name, tt.args.val, tt.args.ruleVal); (err != nil) != tt.wantErr {
+				t.Errorf("boolRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func Test_numericRule(t *testing.T) {
 	type args struct {
 		name    string
@@ -1284,6 +1335,26 @@ func Test_betweenRule(t *testing.T) {
 			wantErr:   true,
 			wantPanic: false,
 		},
+		{
+			name: "test between rule with integer above 2^53",
+			args: args{
+				name:    "id",
+				val:     int64(9007199254740993),
+				ruleVal: "0,9007199254740992",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
+		{
+			name: "test between rule with big.Int",
+			args: args{
+				name:    "id",
+				val:     big.NewInt(5),
+				ruleVal: "1,10",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1371,6 +1442,66 @@ func Test_minRule(t *testing.T) {
 			wantErr:   true,
 			wantPanic: false,
 		},
+		{
+			name: "test min rule with integer above 2^53",
+			args: args{
+				name:    "id",
+				val:     int64(9007199254740993),
+				ruleVal: "9007199254740993",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test min rule with integer below rule val above 2^53",
+			args: args{
+				name:    "id",
+				val:     int64(9007199254740992),
+				ruleVal: "9007199254740993",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
+		{
+			name: "test min rule with big.Int",
+			args: args{
+				name:    "id",
+				val:     new(big.Int).Lsh(big.NewInt(1), 100),
+				ruleVal: "1e30",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test min rule with big.Rat",
+			args: args{
+				name:    "price",
+				val:     big.NewRat(1, 3),
+				ruleVal: "0.3333333333333333333",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test min rule with float and exact decimal rule val",
+			args: args{
+				name:    "price",
+				val:     0.1,
+				ruleVal: "0.1",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test min rule with infinite float",
+			args: args{
+				name:    "price",
+				val:     math.Inf(-1),
+				ruleVal: "0",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1458,6 +1589,36 @@ func Test_maxRule(t *testing.T) {
 			wantErr:   true,
 			wantPanic: false,
 		},
+		{
+			name: "test max rule with integer above 2^53",
+			args: args{
+				name:    "id",
+				val:     int64(9007199254740993),
+				ruleVal: "9007199254740992",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
+		{
+			name: "test max rule with float and exact decimal rule val",
+			args: args{
+				name:    "price",
+				val:     0.1,
+				ruleVal: "0.1",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test max rule with big.Rat",
+			args: args{
+				name:    "price",
+				val:     big.NewRat(1, 3),
+				ruleVal: "0.3333333333333333333",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2965,6 +3126,15 @@ func Test_timeFormatRule(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "test timeFormatRule with time.Time",
+			args: args{
+				name:    "updatedAt",
+				val:     time.Date(1973, 10, 6, 0, 0, 0, 0, time.UTC),
+				ruleVal: "02/01/2006",
+			},
+			wantErr: false,
+		},
 		{
 			name: "test timeFormatRule with unsuitable data",
 			args: args{
@@ -3610,6 +3780,140 @@ func Test_extNotInRule(t *testing.T) {
 	}
 }
 
+func Test_mimeRule(t *testing.T) {
+	type args struct {
+		name    string
+		val     interface{}
+		ruleVal string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantPanic bool
+		wantErr   bool
+	}{
+		{
+			name: "test mimeRule",
+			args: args{
+				name:    "file",
+				val:     &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{"Content-Type": {"image/png"}}},
+				ruleVal: "image/png",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test mimeRule with wildcard",
+			args: args{
+				name:    "file",
+				val:     &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{"Content-Type": {"image/png"}}},
+				ruleVal: "image/*",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test mimeRule with unsuitable data",
+			args: args{
+				name:    "file",
+				val:     &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{"Content-Type": {"image/png"}}},
+				ruleVal: "application/pdf",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
+		{
+			name: "test mimeRule without content type",
+			args: args{
+				name:    "file",
+				val:     &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{}},
+				ruleVal: "image/png",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
+		{
+			name: "test mimeRule with non-file val",
+			args: args{
+				name:    "file",
+				val:     "bla bla",
+				ruleVal: "image/png",
+			},
+			wantErr:   false,
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("mimeRule() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if err := mimeRule(tt.args.name, tt.args.val, tt.args.ruleVal); (err != nil) != tt.wantErr {
+				t.Errorf("mimeRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mimeInRule(t *testing.T) {
+	type args struct {
+		name    string
+		val     interface{}
+		ruleVal string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantPanic bool
+		wantErr   bool
+	}{
+		{
+			name: "test mimeInRule",
+			args: args{
+				name:    "file",
+				val:     &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{"Content-Type": {"image/png"}}},
+				ruleVal: "application/pdf,image/*",
+			},
+			wantErr:   false,
+			wantPanic: false,
+		},
+		{
+			name: "test mimeInRule with unsuitable data",
+			args: args{
+				name:    "file",
+				val:     &multipart.FileHeader{Filename: "a.png", Header: textproto.MIMEHeader{"Content-Type": {"image/png"}}},
+				ruleVal: "application/pdf,text/plain",
+			},
+			wantErr:   true,
+			wantPanic: false,
+		},
+		{
+			name: "test mimeInRule with non-file val",
+			args: args{
+				name:    "file",
+				val:     "bla bla",
+				ruleVal: "image/png",
+			},
+			wantErr:   false,
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("mimeInRule() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if err := mimeInRule(tt.args.name, tt.args.val, tt.args.ruleVal); (err != nil) != tt.wantErr {
+				t.Errorf("mimeInRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func Test_uuidRule(t *testing.T) {
 	type args struct {
 		name    string
@@ -3723,3 +4027,9 @@ func Test_phoneNumberRule(t *testing.T) {
 		})
 	}
 }
+
+func Test_sourceRule(t *testing.T) {
+	if err := sourceRule("role", "admin", "body"); err != nil {
+		t.Errorf("sourceRule() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,195 @@
+package valdn
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Schema validates structs of type T by the rules of their tags and the rules it's built with.
+// It's built once per type by NewSchema, which checks its rules, so validating by it doesn't panic for unknown rules.
+// It's safe for concurrent use.
+type Schema[T any] struct {
+	rules Rules
+	opts  []Option
+	typ   reflect.Type
+}
+
+// Result is the result of validating a value of type T.
+type Result[T any] struct {
+	// Value is the validated value.
+	Value T
+	// Errors has the errors of Value keyed by the paths of its fields.
+	Errors Errors
+
+	schema     *Schema[T]
+	pathFormat PathFormatter
+}
+
+// schemas has the schemas ValidateStruct builds by the struct tags of their types.
+var schemas sync.Map
+
+// NewSchema returns a Schema of struct type T validated by rules and the rules of its struct tags.
+// opts are used by every validation of the schema, e.g. WithPathFormat.
+// It panics if T is not kind of struct, if rules or tags of T (or of its nested structs) have a rule that is not
+// registered, or if rules are keyed by a path that T doesn't have.
+func NewSchema[T any](rules Rules, opts ...Option) *Schema[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Errorf("NewSchema: T must be kind of struct got %v", typ.Kind()))
+	}
+	for name, fieldRules := range rules {
+		if name != RootName && name != "*" && !hasTypePath(typ, strings.Split(strings.TrimPrefix(name, "."), ".")) {
+			panic(fmt.Errorf("NewSchema: %v has no field %v", typ, name))
+		}
+		checkRulesExist(name, fieldRules)
+	}
+	checkTagRules(typ, typ.String(), make(map[reflect.Type]bool))
+	return &Schema[T]{rules: copyRules(rules), opts: append([]Option{}, opts...), typ: typ}
+}
+
+// ValidateStruct validates struct v by the rules of its struct tags and returns Result.
+// The Schema of T is built once and reused, opts are used by this validation only.
+// It panics if T is not kind of struct, or if tags of T have a rule that is not registered.
+func ValidateStruct[T any](v T, opts ...Option) Result[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	s, ok := schemas.Load(typ)
+	if !ok {
+		s, _ = schemas.LoadOrStore(typ, NewSchema[T](Rules{}))
+	}
+	return s.(*Schema[T]).Validate(v, opts...)
+}
+
+// Validate validates v by the rules of s and returns Result, opts are added to the options of s.
+func (s *Schema[T]) Validate(v T, opts ...Option) Result[T] {
+	opts = append(append([]Option{}, s.opts...), opts...)
+	errs := ValidateCollection(v, copyRules(s.rules), opts...)
+	return Result[T]{Value: v, Errors: errs, schema: s, pathFormat: newConfig(opts).pathFormat}
+}
+
+// Field returns the name errors of a field of T are keyed by, field returns a pointer to the field of t,
+// e.g. schema.Field(func(u *User) any { return &u.Address.City }) returns Address.City.
+// Fields of nested structs are supported, but not fields of items of slices, arrays or maps.
+// It panics if field doesn't return a pointer to a field of t.
+func (s *Schema[T]) Field(field func(t *T) any) string {
+	return formatFieldPath(s.fieldPath(field), newConfig(s.opts).pathFormat)
+}
+
+// fieldPath returns the path of the field of T field returns a pointer to, see Field.
+func (s *Schema[T]) fieldPath(field func(t *T) any) Path {
+	var t T
+	ptr := reflect.ValueOf(field(&t))
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		panic(fmt.Errorf("Field: field must return a pointer to a field of %v", s.typ))
+	}
+	offset := ptr.Pointer() - reflect.ValueOf(&t).Pointer()
+	p, ok := fieldPath(s.typ, offset, ptr.Type().Elem(), nil)
+	if !ok {
+		panic(fmt.Errorf("Field: field must return a pointer to a field of %v", s.typ))
+	}
+	return p
+}
+
+// Valid reports weather the value of r has no errors.
+func (r Result[T]) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Field returns the error of a field of the value of r, or an empty string if it has no error, see Schema.Field.
+// e.g. result.Field(func(u *User) any { return &u.Email }).
+// The field is named by the path format r is validated with, including the options passed to Validate.
+func (r Result[T]) Field(field func(t *T) any) string {
+	return r.Errors[formatFieldPath(r.schema.fieldPath(field), r.pathFormat)]
+}
+
+// formatFieldPath formats the path p of a field by f, or in dotted notation if f is nil.
+func formatFieldPath(p Path, f PathFormatter) string {
+	if f == nil {
+		return p.String()
+	}
+	return f(p)
+}
+
+// fieldPath returns the path of the field of typ at offset whose type is fieldTyp, p is the path of typ.
+// It reports weather typ has the field.
+func fieldPath(typ reflect.Type, offset uintptr, fieldTyp reflect.Type, p Path) (Path, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if offset < f.Offset || (f.Type.Size() > 0 && offset >= f.Offset+f.Type.Size()) {
+			continue
+		}
+		if offset == f.Offset && f.Type == fieldTyp {
+			return p.join(f.Name), true
+		}
+		if f.Type.Kind() == reflect.Struct {
+			if nested, ok := fieldPath(f.Type, offset-f.Offset, fieldTyp, p.join(f.Name)); ok {
+				return nested, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// hasTypePath reports weather values of typ may have a field at path segments.
+// Items of slices and arrays are named by their indexes or *, and keys of maps and fields of interfaces by anything.
+func hasTypePath(typ reflect.Type, segments []string) bool {
+	if len(segments) == 0 {
+		return true
+	}
+	segment := segments[0]
+	switch typ.Kind() {
+	case reflect.Struct:
+		if segment == "*" {
+			return len(segments) == 1
+		}
+		f, ok := typ.FieldByName(segment)
+		return ok && f.IsExported() && hasTypePath(f.Type, segments[1:])
+	case reflect.Slice, reflect.Array:
+		if _, err := strconv.Atoi(segment); err != nil && segment != "*" {
+			return false
+		}
+		return hasTypePath(typ.Elem(), segments[1:])
+	case reflect.Map:
+		return hasTypePath(typ.Elem(), segments[1:])
+	case reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// checkTagRules panics if the tags of struct typ named name, or of its nested structs, have a rule that is not
+// registered, checked has the types that are already checked.
+func checkTagRules(typ reflect.Type, name string, checked map[reflect.Type]bool) {
+	for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map ||
+		typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || checked[typ] {
+		return
+	}
+	checked[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if tRules := f.Tag.Get(TagName); tRules != "" {
+			checkRulesExist(name+"."+f.Name, strings.Split(tRules, TagSeparator))
+		}
+		checkTagRules(f.Type, name+"."+f.Name, checked)
+	}
+}
+
+// checkRulesExist panics if one of the rules of name, or of the aliases it has, is not registered.
+func checkRulesExist(name string, rules []string) {
+	for i, r := range expandAliases(rules) {
+		if r == "" || (i == 0 && r == "skip") {
+			continue
+		}
+		if rName, _, _, ok := getRuleInfo(r); !ok {
+			panic(fmt.Errorf("unknown rule: %v of %v", rName, name))
+		}
+		if _, group, ok := splitGroupRule(r); ok {
+			checkGroup(group)
+		}
+	}
+}
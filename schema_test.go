@@ -0,0 +1,201 @@
+package valdn
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestAddress struct {
+	Street string
+	City   string `valdn:"required|minLen:2"`
+}
+
+type schemaTestUser struct {
+	Name      string `valdn:"required"`
+	Email     string `valdn:"required|email"`
+	Age       int
+	Address   schemaTestAddress
+	Tags      []string
+	Meta      map[string]interface{}
+	CreatedAt time.Time
+	internal  string
+}
+
+func Test_NewSchema(t *testing.T) {
+	type badTag struct {
+		Name string `valdn:"required|unknownRule"`
+	}
+	type badNestedTag struct {
+		Items []badTag
+	}
+	tests := []struct {
+		name      string
+		new       func()
+		wantPanic bool
+	}{
+		{
+			name: "test NewSchema",
+			new: func() {
+				NewSchema[schemaTestUser](Rules{
+					"$":              {"required"},
+					"Age":            {"min:18"},
+					"Address.Street": {"required"},
+					"Tags.*":         {"kind:string"},
+					"Tags.0":         {"required"},
+					"Meta.any.thing": {"required"},
+					"*":              {"required"},
+				})
+			},
+		},
+		{
+			name:      "test NewSchema with non struct type",
+			new:       func() { NewSchema[[]string](Rules{}) },
+			wantPanic: true,
+		},
+		{
+			name:      "test NewSchema with unknown rule",
+			new:       func() { NewSchema[schemaTestUser](Rules{"Age": {"unknownRule"}}) },
+			wantPanic: true,
+		},
+		{
+			name:      "test NewSchema with unknown field",
+			new:       func() { NewSchema[schemaTestUser](Rules{"Address.Zip": {"required"}}) },
+			wantPanic: true,
+		},
+		{
+			name:      "test NewSchema with unexported field",
+			new:       func() { NewSchema[schemaTestUser](Rules{"internal": {"required"}}) },
+			wantPanic: true,
+		},
+		{
+			name:      "test NewSchema with nested field of scalar",
+			new:       func() { NewSchema[schemaTestUser](Rules{"Age.Value": {"required"}}) },
+			wantPanic: true,
+		},
+		{
+			name:      "test NewSchema with unknown tag rule",
+			new:       func() { NewSchema[badTag](Rules{}) },
+			wantPanic: true,
+		},
+		{
+			name:      "test NewSchema with unknown tag rule of nested struct",
+			new:       func() { NewSchema[badNestedTag](Rules{}) },
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("NewSchema() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			tt.new()
+		})
+	}
+}
+
+func Test_Schema_Validate(t *testing.T) {
+	s := NewSchema[schemaTestUser](Rules{"Age": {"min:18"}, "Tags": {"maxLen:1"}})
+	user := schemaTestUser{Name: "Narmer", Email: "narmer", Age: 17, Address: schemaTestAddress{City: "T"}}
+	got := s.Validate(user)
+	want := Errors{
+		"Email":        GetErrMsg("email", "", "Email", "narmer"),
+		"Age":          GetErrMsg("min", "18", "Age", 17),
+		"Address.City": GetErrMsg("minLen", "2", "Address.City", "T"),
+	}
+	if !reflect.DeepEqual(got.Errors, want) || got.Valid() || !reflect.DeepEqual(got.Value, user) {
+		t.Errorf("Schema.Validate() = %v, want %v", got.Errors, want)
+	}
+	if got.Field(func(u *schemaTestUser) any { return &u.Address.City }) != want["Address.City"] {
+		t.Errorf("Result.Field() = %v, want %v", got.Field(func(u *schemaTestUser) any { return &u.Address.City }), want["Address.City"])
+	}
+	if got.Field(func(u *schemaTestUser) any { return &u.Name }) != "" {
+		t.Errorf("Result.Field() of valid field = %v, want empty", got.Field(func(u *schemaTestUser) any { return &u.Name }))
+	}
+
+	got = s.Validate(user, WithPathFormat(JSONPointerPath))
+	if _, ok := got.Errors["/Address/City"]; !ok {
+		t.Errorf("Schema.Validate() with path format = %v, want /Address/City error", got.Errors)
+	}
+	if got.Field(func(u *schemaTestUser) any { return &u.Address.City }) != got.Errors["/Address/City"] {
+		t.Errorf("Result.Field() with path format = %v, want %v", got.Field(func(u *schemaTestUser) any { return &u.Address.City }), got.Errors["/Address/City"])
+	}
+
+	valid := schemaTestUser{Name: "Narmer", Email: "narmer@example.com", Age: 30, Address: schemaTestAddress{City: "Thinis"}}
+	if got = s.Validate(valid); !got.Valid() {
+		t.Errorf("Schema.Validate() = %v, want no errors", got.Errors)
+	}
+	// rules of tags are not added to the rules of the schema
+	if !reflect.DeepEqual(s.rules, Rules{"Age": {"min:18"}, "Tags": {"maxLen:1"}}) {
+		t.Errorf("Schema.Validate() changed the rules of the schema to %v", s.rules)
+	}
+}
+
+func Test_Schema_Field(t *testing.T) {
+	s := NewSchema[schemaTestUser](Rules{})
+	tests := []struct {
+		name      string
+		field     func(u *schemaTestUser) any
+		schema    *Schema[schemaTestUser]
+		want      string
+		wantPanic bool
+	}{
+		{name: "test Field with first field", field: func(u *schemaTestUser) any { return &u.Name }, schema: s, want: "Name"},
+		{name: "test Field with field", field: func(u *schemaTestUser) any { return &u.Age }, schema: s, want: "Age"},
+		{name: "test Field with nested struct", field: func(u *schemaTestUser) any { return &u.Address }, schema: s, want: "Address"},
+		{name: "test Field with first field of nested struct", field: func(u *schemaTestUser) any { return &u.Address.Street }, schema: s, want: "Address.Street"},
+		{name: "test Field with field of nested struct", field: func(u *schemaTestUser) any { return &u.Address.City }, schema: s, want: "Address.City"},
+		{name: "test Field with slice", field: func(u *schemaTestUser) any { return &u.Tags }, schema: s, want: "Tags"},
+		{
+			name:   "test Field with path format",
+			field:  func(u *schemaTestUser) any { return &u.Address.City },
+			schema: NewSchema[schemaTestUser](Rules{}, WithPathFormat(JSONPointerPath)),
+			want:   "/Address/City",
+		},
+		{name: "test Field with value", field: func(u *schemaTestUser) any { return u.Name }, schema: s, wantPanic: true},
+		{name: "test Field with nil", field: func(u *schemaTestUser) any { return (*string)(nil) }, schema: s, wantPanic: true},
+		{name: "test Field with other pointer", field: func(u *schemaTestUser) any { return new(string) }, schema: s, wantPanic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("Schema.Field() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := tt.schema.Field(tt.field); got != tt.want {
+				t.Errorf("Schema.Field() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateStruct_generic(t *testing.T) {
+	got := ValidateStruct(schemaTestUser{Email: "narmer@example.com", Address: schemaTestAddress{City: "Thinis"}})
+	want := Errors{"Name": GetErrMsg("required", "", "Name", "")}
+	if !reflect.DeepEqual(got.Errors, want) {
+		t.Errorf("ValidateStruct() = %v, want %v", got.Errors, want)
+	}
+	if _, ok := schemas.Load(reflect.TypeOf(schemaTestUser{})); !ok {
+		t.Errorf("ValidateStruct() didn't keep the schema of %T", schemaTestUser{})
+	}
+	got = ValidateStruct(schemaTestUser{Email: "narmer@example.com", Address: schemaTestAddress{City: "Thinis"}}, WithPathFormat(JSONPointerPath))
+	if got.Field(func(u *schemaTestUser) any { return &u.Name }) != "/Name is required" {
+		t.Errorf("Result.Field() with path format = %v, want %v", got.Field(func(u *schemaTestUser) any { return &u.Name }), "/Name is required")
+	}
+	got = ValidateStruct(schemaTestUser{Name: "Narmer", Email: "narmer@example.com", Address: schemaTestAddress{City: "Thinis"}})
+	if !got.Valid() {
+		t.Errorf("ValidateStruct() = %v, want no errors", got.Errors)
+	}
+
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("ValidateStruct() didn't panic with unknown tag rule")
+		}
+	}()
+	ValidateStruct(struct {
+		Name string `valdn:"unknownRule"`
+	}{})
+}
@@ -0,0 +1,258 @@
+package valdn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonStream validates a JSON document token by token, so only its scalars are decoded.
+type jsonStream struct {
+	dec   *json.Decoder
+	v     *validation
+	c     *config
+	known func(p Path) bool
+	// build is true while an object selected by a discriminator is read, its values are kept to validate it after
+	// it's read, see addDiscriminatorRules.
+	build bool
+}
+
+// ValidateJSONStream reads JSON from r and validates it by rules while it streams, and returns Errors.
+// Arrays and objects are walked token by token, so a multi-gigabyte array is validated item by item with
+// bounded memory. Only scalars are decoded, arrays and objects are validated after their items by their length
+// and keys (e.g. required, kind:slice, maxLen), so their items are validated even if they have errors.
+// Rules and errors are named the same way as ValidateJSON.
+// Objects selected by WithDiscriminator are read in memory, and validated by the rules of their cases after they're
+// read, so they're validated like ValidateJSON validates them.
+// It panics if r doesn't have valid JSON, it can't be read, or it exceeds the limits set using WithLimits.
+// WithStrictJSON rejects duplicate keys and WithDisallowUnknownFields rejects unknown keys by JSONError
+// at the offset where the key ends, data after the top-level value is always rejected.
+// It panics if one of the rules is not registered.
+func ValidateJSONStream(r io.Reader, rules Rules, opts ...Option) Errors {
+	c := newConfig(opts)
+	s := &jsonStream{
+		dec: json.NewDecoder(c.limits.limitReader(r)),
+		v:   createNewValidation(rules),
+		c:   c,
+	}
+	s.dec.UseNumber()
+	s.v.formatPath = c.pathFormat
+	s.v.discriminators = c.discriminators
+	if c.knownFields {
+		s.known = knownFields(discriminatorRules(rules, c.discriminators))
+	}
+
+	s.v.registerField(RootName)
+	s.value(nil, "", s.v.getParentRules(""), 0, true)
+	end := s.dec.InputOffset()
+	if _, err := s.dec.Token(); err == nil {
+		panic(&JSONError{Offset: int(end), Err: ErrTrailingData})
+	} else if err != io.EOF {
+		panic(err)
+	}
+
+	s.v.validateNonExistRequiredFields()
+	return s.v.errors
+}
+
+// value reads the value at p, named name (the dotted name of p), and validates it by rules if validate is true,
+// depth is its nesting depth. Names of nested values are joined to name, so they're not built from their paths.
+// It returns the value while an object selected by a discriminator is read, otherwise its objects and arrays are not
+// kept (their keys and length are).
+func (s *jsonStream) value(p Path, name string, rules []string, depth int, validate bool) interface{} {
+	if validate && !s.build && s.discriminated(p) {
+		s.build = true
+		val := parseJSONVal(s.value(p, name, rules, depth, false))
+		s.build = false
+		s.v.validateByType(p, name, rules, reflect.TypeOf(val), val)
+		return nil
+	}
+
+	tok, err := s.dec.Token()
+	if err != nil {
+		panic(err)
+	}
+
+	// only fields that have rules are registered, so the fields of huge documents are not kept
+	if _, ok := s.v.rules[name]; ok && len(p) > 0 {
+		s.v.registerField(name)
+	}
+	if len(rules) > 0 && rules[0] == "skip" {
+		validate = false
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		if validate {
+			s.v.validate(p, name, parseJSONVal(tok), rules)
+		}
+		return tok
+	}
+
+	prefix := name + "."
+	if len(p) == 0 {
+		prefix = ""
+	}
+	// schema rules and containers of read objects are added when they're validated
+	if !s.build {
+		s.v.addSchemaRules(p, rules)
+		// only collections that have rules of nested fields are recorded, to resolve the names of their missing fields
+		if len(p) == 0 || s.hasNestedRules(prefix) {
+			s.v.containers[name] = delim == '['
+		}
+	}
+	var val interface{}
+	l := s.c.limits
+	if delim == '[' {
+		n := 0
+		items := make([]interface{}, 0)
+		for s.dec.More() {
+			n++
+			if l.MaxArrayLen > 0 && n > l.MaxArrayLen {
+				panic(fmt.Errorf("%w: %v has more than %v items", ErrArrayTooLong, rootName(name), l.MaxArrayLen))
+			}
+			s.checkDepth(name, depth)
+			itemName := prefix + strconv.Itoa(n-1)
+			item := s.value(p.child(n-1), itemName, s.v.getNestedRules(itemName, name), depth+1, validate)
+			if s.build {
+				items = append(items, item)
+			}
+		}
+		val = make([]struct{}, n)
+		if s.build {
+			val = items
+		}
+	} else {
+		keys := make(map[string]interface{})
+		for s.dec.More() {
+			s.checkDepth(name, depth)
+			s.key(p, name, prefix, keys, depth, validate)
+			if l.MaxKeys > 0 && len(keys) > l.MaxKeys {
+				panic(fmt.Errorf("%w: %v has more than %v keys", ErrTooManyKeys, rootName(name), l.MaxKeys))
+			}
+		}
+		val = keys
+	}
+	// closing delimiter
+	if _, err = s.dec.Token(); err != nil {
+		panic(err)
+	}
+	if validate {
+		s.v.validate(p, name, val, rules)
+	}
+	return val
+}
+
+// discriminated reports weather the value at p is selected by one of the discriminators.
+func (s *jsonStream) discriminated(p Path) bool {
+	for _, d := range s.v.discriminators {
+		if d.matches(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNestedRules reports weather fields whose names start with prefix (the prefix of the names of the values nested
+// in a value, see Path.prefix) have rules.
+func (s *jsonStream) hasNestedRules(prefix string) bool {
+	for k := range s.v.rules {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// key reads a key of the object at p, of dotted name, and its value, and adds the key to keys with the value returned
+// by value. prefix is the prefix of the names of the values of the object, depth is its nesting depth, and the value
+// is validated if validate is true.
+func (s *jsonStream) key(p Path, name, prefix string, keys map[string]interface{}, depth int, validate bool) {
+	tok, err := s.dec.Token()
+	if err != nil {
+		panic(err)
+	}
+	key := tok.(string)
+	keyPath := p.child(key)
+	if _, ok := keys[key]; ok && s.c.strictJSON {
+		panic(&JSONError{Offset: int(s.dec.InputOffset()), Pointer: JSONPointerPath(keyPath), Err: ErrDuplicateKey})
+	}
+	if s.known != nil && !s.known(keyPath) {
+		panic(&JSONError{Offset: int(s.dec.InputOffset()), Pointer: JSONPointerPath(keyPath), Err: ErrUnknownField})
+	}
+	keys[key] = s.value(keyPath, prefix+key, s.v.getNestedRules(prefix+key, name), depth+1, validate)
+}
+
+// checkDepth panics with ErrTooDeep if the items of the value named name, of nesting depth, exceed MaxDepth,
+// or maxJSONDepth if MaxDepth is not set.
+func (s *jsonStream) checkDepth(name string, depth int) {
+	if err := s.c.limits.checkJSONDepth(name, depth); err != nil {
+		panic(err)
+	}
+}
+
+// ValidateNDJSON reads newline delimited JSON from r and validates every record (line) by rules like ValidateJSON.
+// handle is called with the line number (starting at 1) and the Errors of every record, blank lines are skipped.
+// Records that are not valid JSON, or exceed the limits set using WithLimits, get their error named RootName.
+// MaxBodyBytes limits the size of every record (line) not the size of r, records that exceed it are read to their
+// end but not kept, so only one record is kept in memory at a time. Wrap r (e.g. by http.MaxBytesReader) to limit
+// the size of the stream.
+// It stops when r is read, handle returns an error, or reading r fails, and returns the error.
+// It panics if one of the rules is not registered.
+func ValidateNDJSON(r io.Reader, rules Rules, handle func(line int, errs Errors) error, opts ...Option) error {
+	c := newConfig(opts)
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		b, tooLarge, err := readRecord(br, c.limits)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		var errs Errors
+		if tooLarge != nil {
+			errs = Errors{RootName: tooLarge.Error()}
+		} else if len(bytes.TrimSpace(b)) > 0 {
+			errs = validateRecord(b, rules, c, opts)
+		}
+		if errs != nil {
+			if hErr := handle(line, errs); hErr != nil {
+				return hErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// readRecord reads a line of br. Lines larger than MaxBodyBytes of l are read to their end but not kept,
+// and their ErrRecordTooLarge error is returned as tooLarge.
+func readRecord(br *bufio.Reader, l Limits) (line []byte, tooLarge error, err error) {
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if tooLarge == nil {
+			line = append(line, chunk...)
+			// the line break is not a part of the record
+			if size := int64(len(bytes.TrimRight(line, "\r\n"))); l.MaxBodyBytes > 0 && size > l.MaxBodyBytes {
+				tooLarge = fmt.Errorf("%w: more than %v bytes", ErrRecordTooLarge, l.MaxBodyBytes)
+				line = nil
+			}
+		}
+		if err != bufio.ErrBufferFull {
+			return line, tooLarge, err
+		}
+	}
+}
+
+// validateRecord validates JSON record b by rules, its decoding error is returned named RootName.
+func validateRecord(b []byte, rules Rules, c *config, opts []Option) Errors {
+	root, err := decodeJSON(b, rules, c)
+	if err != nil {
+		return Errors{RootName: err.Error()}
+	}
+	return validateRoot(root, rules, opts)
+}
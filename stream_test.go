@@ -0,0 +1,352 @@
+package valdn
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ValidateJSONStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		val       string
+		rules     Rules
+		opts      []Option
+		want      Errors
+		wantErr   error
+		wantPanic bool
+	}{
+		{
+			name:  "test ValidateJSONStream with array root",
+			val:   `[{"name":"Narmer","tags":["a"]},{"name":1,"tags":[]},{"tags":["b","c"]}]`,
+			rules: Rules{"$": {"required", "kind:slice", "maxLen:3"}, "*": {"kind:map"}, "0.name": {"required"}, "1.name": {"kind:string"}, "1.tags": {"required"}, "2.name": {"required"}, "2.tags": {"maxLen:1"}},
+			want: Errors{
+				"1.name": GetErrMsg("kind", "string", "1.name", 1),
+				"1.tags": GetErrMsg("required", "", "1.tags", ""),
+				"2.name": GetErrMsg("required", "", "2.name", ""),
+				"2.tags": GetErrMsg("maxLen", "1", "2.tags", ""),
+			},
+		},
+		{
+			name:  "test ValidateJSONStream with invalid root",
+			val:   `[1,2,3]`,
+			rules: Rules{"$": {"maxLen:2"}, "*": {"max:2"}},
+			want: Errors{
+				"$": GetErrMsg("maxLen", "2", "$", ""),
+				"2": GetErrMsg("max", "2", "2", 3),
+			},
+		},
+		{
+			name:  "test ValidateJSONStream with object root",
+			val:   `{"user":{"name":"Narmer","age":17},"id":9007199254740993,"skipped":{"x":1},"n":null}`,
+			rules: Rules{"user": {"required", "kind:map"}, "user.age": {"min:18"}, "id": {"int", "max:9007199254740992"}, "skipped": {"skip"}, "skipped.x": {"kind:string"}, "n": {"required"}, "email": {"required"}},
+			want: Errors{
+				"user.age": GetErrMsg("min", "18", "user.age", 17),
+				"id":       GetErrMsg("max", "9007199254740992", "id", 0),
+				"n":        GetErrMsg("required", "", "n", ""),
+				"email":    GetErrMsg("required", "", "email", ""),
+			},
+		},
+		{
+			name:  "test ValidateJSONStream with scalar root",
+			val:   ` "Narmer" `,
+			rules: Rules{"$": {"kind:int"}},
+			want:  Errors{"$": GetErrMsg("kind", "int", "$", "Narmer")},
+		},
+		{
+			name:  "test ValidateJSONStream with path format",
+			val:   `{"items":[{"name":1}]}`,
+			rules: Rules{"items.*": {"kind:map"}, "items.0.name": {"kind:string"}},
+			opts:  []Option{WithPathFormat(JSONPointerPath)},
+			want:  Errors{"/items/0/name": GetErrMsg("kind", "string", "/items/0/name", 1)},
+		},
+		{
+			name:      "test ValidateJSONStream with malformed json",
+			val:       `[{"name":"Narmer"},]`,
+			rules:     Rules{},
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateJSONStream with trailing data",
+			val:       `[1] [2]`,
+			rules:     Rules{},
+			wantErr:   ErrTrailingData,
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateJSONStream with duplicate key",
+			val:       `{"name":"a","name":"b"}`,
+			rules:     Rules{},
+			opts:      []Option{WithStrictJSON()},
+			wantErr:   ErrDuplicateKey,
+			wantPanic: true,
+		},
+		{
+			name:  "test ValidateJSONStream with duplicate key without strict json",
+			val:   `{"name":"a","name":"b"}`,
+			rules: Rules{"name": {"required"}},
+			want:  Errors{},
+		},
+		{
+			name:      "test ValidateJSONStream with unknown field",
+			val:       `[{"name":"a","role":"admin"}]`,
+			rules:     Rules{"*": {"kind:map"}, "0.name": {"required"}},
+			opts:      []Option{WithDisallowUnknownFields()},
+			wantErr:   ErrUnknownField,
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateJSONStream with too long array",
+			val:       `[1,2,3]`,
+			rules:     Rules{},
+			opts:      []Option{WithLimits(Limits{MaxArrayLen: 2})},
+			wantErr:   ErrArrayTooLong,
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateJSONStream with too many keys",
+			val:       `{"a":1,"b":2}`,
+			rules:     Rules{},
+			opts:      []Option{WithLimits(Limits{MaxKeys: 1})},
+			wantErr:   ErrTooManyKeys,
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateJSONStream with too deep json",
+			val:       `[[[1]]]`,
+			rules:     Rules{},
+			opts:      []Option{WithLimits(Limits{MaxDepth: 2})},
+			wantErr:   ErrTooDeep,
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateJSONStream with too large json",
+			val:       `[` + strings.Repeat(`1,`, 100) + `1]`,
+			rules:     Rules{},
+			opts:      []Option{WithLimits(Limits{MaxBodyBytes: 50})},
+			wantErr:   ErrBodyTooLarge,
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				e := recover()
+				if (e != nil) != tt.wantPanic {
+					t.Errorf("ValidateJSONStream() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+				if err, _ := e.(error); tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("ValidateJSONStream() panic = %v, wantErr %v", e, tt.wantErr)
+				}
+			}()
+			if got := ValidateJSONStream(strings.NewReader(tt.val), tt.rules, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateJSONStream() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateJSONStream_matchesValidateJSON(t *testing.T) {
+	val := `{"items":[{"sku":"a1","qty":2},{"sku":"","qty":0.5}],"note":null}`
+	rules := Rules{
+		"items":       {"required", "kind:slice", "minLen:1"},
+		"items.*":     {"kind:map"},
+		"items.0.sku": {"required"},
+		"items.1.sku": {"required"},
+		"items.1.qty": {"int"},
+		"note":        {"required"},
+	}
+	want := ValidateJSON(val, rules)
+	if got := ValidateJSONStream(strings.NewReader(val), rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateJSONStream() = %v, ValidateJSON() = %v", got, want)
+	}
+}
+
+func Test_ValidateJSONStream_digitKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		val   string
+		rules Rules
+	}{
+		{
+			name:  "test ValidateJSONStream with digit map keys",
+			val:   `{"m":{"1":1}}`,
+			rules: Rules{"m": {"kind:map"}, "m.0": {"required"}, "m.1": {"kind:string"}},
+		},
+		{
+			name:  "test ValidateJSONStream with wildcard rules of keys with dots",
+			val:   `{"a.b":1,"m":{"c.d":2}}`,
+			rules: Rules{"*": {"max:0"}, "m": {"kind:map"}, "m.*": {"max:1"}},
+		},
+		{
+			name:  "test ValidateJSONStream with slice indexes",
+			val:   `{"m":[1]}`,
+			rules: Rules{"m": {"kind:slice"}, "m.0": {"kind:string"}, "m.1": {"required"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := ValidateJSON(tt.val, tt.rules, WithPathFormat(BracketPath))
+			got := ValidateJSONStream(strings.NewReader(tt.val), tt.rules, WithPathFormat(BracketPath))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ValidateJSONStream() = %v, ValidateJSON() = %v", got, want)
+			}
+		})
+	}
+}
+
+// repeatReader reads a JSON array of n copies of item.
+type repeatReader struct {
+	item    []byte
+	n       int
+	written int
+	buf     []byte
+	closed  bool
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	for len(r.buf) < len(p) && !r.closed {
+		switch {
+		case r.written == 0 && r.buf == nil:
+			r.buf = []byte{'['}
+		case r.written == r.n:
+			r.buf = append(r.buf, ']')
+			r.closed = true
+		default:
+			if r.written > 0 {
+				r.buf = append(r.buf, ',')
+			}
+			r.buf = append(r.buf, r.item...)
+			r.written++
+		}
+	}
+	if len(r.buf) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func Test_ValidateJSONStream_largeArray(t *testing.T) {
+	r := &repeatReader{item: []byte(`{"name":"Narmer","tags":["a","b"]}`), n: 20000}
+	got := ValidateJSONStream(r, Rules{"$": {"required", "minLen:20000"}, "*": {"kind:map"}, "19999.name": {"required"}})
+	if len(got) != 0 {
+		t.Errorf("ValidateJSONStream() = %v, want no errors", got)
+	}
+}
+
+func Test_ValidateNDJSON(t *testing.T) {
+	errHandler := errors.New("stop")
+	type record struct {
+		line int
+		errs Errors
+	}
+	tests := []struct {
+		name       string
+		val        string
+		rules      Rules
+		opts       []Option
+		handlerErr error
+		want       []record
+		wantErr    error
+		wantPanic  bool
+	}{
+		{
+			name:  "test ValidateNDJSON",
+			val:   "{\"name\":\"Narmer\"}\n\n{\"name\":1}\n{\"age\":30}",
+			rules: Rules{"name": {"required", "kind:string"}},
+			want: []record{
+				{line: 1, errs: Errors{}},
+				{line: 3, errs: Errors{"name": GetErrMsg("kind", "string", "name", 1)}},
+				{line: 4, errs: Errors{"name": GetErrMsg("required", "", "name", "")}},
+			},
+		},
+		{
+			name:  "test ValidateNDJSON with malformed record",
+			val:   "{\"name\":\"Narmer\"}\r\n{\"name\":\n[1]\n",
+			rules: Rules{"name": {"required"}},
+			want: []record{
+				{line: 1, errs: Errors{}},
+				{line: 2, errs: Errors{"$": "unexpected end of JSON input"}},
+				{line: 3, errs: Errors{"name": GetErrMsg("required", "", "name", "")}},
+			},
+		},
+		{
+			name:  "test ValidateNDJSON with too deep record",
+			val:   "[[1]]\n[1]",
+			rules: Rules{},
+			opts:  []Option{WithLimits(Limits{MaxDepth: 1})},
+			want: []record{
+				{line: 1, errs: Errors{"$": "value nested too deep: 0 is nested more than 1 levels"}},
+				{line: 2, errs: Errors{}},
+			},
+		},
+		{
+			name:  "test ValidateNDJSON with too many keys of root",
+			val:   "{\"a\":1,\"b\":2}",
+			rules: Rules{},
+			opts:  []Option{WithLimits(Limits{MaxKeys: 1})},
+			want:  []record{{line: 1, errs: Errors{"$": "too many object keys: $ has more than 1 keys"}}},
+		},
+		{
+			name:       "test ValidateNDJSON with handler error",
+			val:        "{\"name\":1}\n{\"name\":2}",
+			rules:      Rules{},
+			handlerErr: errHandler,
+			want:       []record{{line: 1, errs: Errors{}}},
+			wantErr:    errHandler,
+		},
+		{
+			name:  "test ValidateNDJSON with too large record",
+			val:   "{\"name\":\"Narmer\"}\r\n{\"name\":\"" + strings.Repeat("a", 5000) + "\"}\n{\"name\":1}",
+			rules: Rules{"name": {"kind:string"}},
+			opts:  []Option{WithLimits(Limits{MaxBodyBytes: 17})},
+			want: []record{
+				{line: 1, errs: Errors{}},
+				{line: 2, errs: Errors{"$": "record too large: more than 17 bytes"}},
+				{line: 3, errs: Errors{"name": GetErrMsg("kind", "string", "name", 1)}},
+			},
+		},
+		{
+			name:  "test ValidateNDJSON with limited records of larger body",
+			val:   strings.Repeat("{\"name\":2}\n", 10),
+			rules: Rules{},
+			opts:  []Option{WithLimits(Limits{MaxBodyBytes: 20})},
+			want: []record{
+				{line: 1, errs: Errors{}}, {line: 2, errs: Errors{}}, {line: 3, errs: Errors{}}, {line: 4, errs: Errors{}},
+				{line: 5, errs: Errors{}}, {line: 6, errs: Errors{}}, {line: 7, errs: Errors{}}, {line: 8, errs: Errors{}},
+				{line: 9, errs: Errors{}}, {line: 10, errs: Errors{}},
+			},
+		},
+		{
+			name:      "test ValidateNDJSON with unknown rule",
+			val:       "{\"name\":1}",
+			rules:     Rules{"name": {"unknown"}},
+			want:      nil,
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("ValidateNDJSON() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			var got []record
+			err := ValidateNDJSON(strings.NewReader(tt.val), tt.rules, func(line int, errs Errors) error {
+				got = append(got, record{line: line, errs: errs})
+				return tt.handlerErr
+			}, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateNDJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateNDJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
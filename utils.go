@@ -2,6 +2,7 @@ package valdn
 
 import (
 	"encoding/json"
+	"math/big"
 	"net"
 	"reflect"
 	"regexp"
@@ -19,6 +20,9 @@ const (
 
 // IsEmpty reports weather val is empty or not.
 func IsEmpty(val interface{}) bool {
+	if val == nil {
+		return true
+	}
 	t := reflect.TypeOf(val)
 	v := reflect.ValueOf(val)
 	switch t.Kind() {
@@ -180,8 +184,11 @@ func IsMap(val interface{}) bool {
 	return reflect.ValueOf(val).Kind() == reflect.Map
 }
 
-// IsInteger reports weather val is integer or not.
+// IsInteger reports weather val is integer (including *big.Int) or not.
 func IsInteger(val interface{}) bool {
+	if _, ok := val.(*big.Int); ok {
+		return true
+	}
 	switch reflect.TypeOf(val).Kind() {
 	case reflect.Uint, reflect.Int, reflect.Uint8, reflect.Int8, reflect.Uint16, reflect.Int16, reflect.Uint32, reflect.Int32, reflect.Uint64, reflect.Int64:
 		return true
@@ -192,6 +199,9 @@ func IsInteger(val interface{}) bool {
 
 // IsUnsignedInteger reports weather val is unsigned integer or not.
 func IsUnsignedInteger(val interface{}) bool {
+	if v, ok := val.(*big.Int); ok {
+		return v.Sign() >= 0
+	}
 	switch reflect.TypeOf(val).Kind() {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return true
@@ -205,8 +215,11 @@ func IsUnsignedInteger(val interface{}) bool {
 	return false
 }
 
-// IsFloat reports weather val is float or not.
+// IsFloat reports weather val is float (including *big.Rat) or not.
 func IsFloat(val interface{}) bool {
+	if _, ok := val.(*big.Rat); ok {
+		return true
+	}
 	switch reflect.TypeOf(val).Kind() {
 	case reflect.Float32, reflect.Float64:
 		return true
@@ -217,6 +230,9 @@ func IsFloat(val interface{}) bool {
 
 // IsUnsignedFloat reports weather val is unsigned float or not.
 func IsUnsignedFloat(val interface{}) bool {
+	if v, ok := val.(*big.Rat); ok {
+		return v.Sign() >= 0
+	}
 	switch reflect.TypeOf(val).Kind() {
 	case reflect.Float32, reflect.Float64:
 		vString := toString(val)
@@ -249,6 +265,9 @@ func IsNumeric(val interface{}) bool {
 
 // IsCollection reports weather val's kins is one of (Array, Slice, Map, Struct) or not.
 func IsCollection(val interface{}) bool {
+	if val == nil {
+		return false
+	}
 	switch reflect.TypeOf(val).Kind() {
 	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
 		return true
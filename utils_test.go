@@ -1,6 +1,7 @@
 package valdn
 
 import (
+	"math/big"
 	"mime/multipart"
 	"net/textproto"
 	"os"
@@ -26,6 +27,11 @@ func Test_IsEmpty(t *testing.T) {
 			args: args{val: "t"},
 			want: false,
 		},
+		{
+			name: "test check if nil is empty",
+			args: args{val: nil},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -998,6 +1004,20 @@ func Test_IsInteger(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "test IsInteger with big.Int",
+			args: args{
+				val: big.NewInt(1),
+			},
+			want: true,
+		},
+		{
+			name: "test IsInteger with big.Rat",
+			args: args{
+				val: big.NewRat(1, 2),
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1059,6 +1079,20 @@ func Test_IsUnsignedInteger(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "test IsUnsignedInteger with big.Int",
+			args: args{
+				val: big.NewInt(1),
+			},
+			want: true,
+		},
+		{
+			name: "test IsUnsignedInteger with negative big.Int",
+			args: args{
+				val: big.NewInt(-1),
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1099,6 +1133,13 @@ func Test_IsFloat(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "test IsFloat with big.Rat",
+			args: args{
+				val: big.NewRat(1, 2),
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1139,6 +1180,20 @@ func Test_IsUnsignedFloat(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "test IsUnsignedFloat with big.Rat",
+			args: args{
+				val: big.NewRat(1, 2),
+			},
+			want: true,
+		},
+		{
+			name: "test IsUnsignedFloat with negative big.Rat",
+			args: args{
+				val: big.NewRat(-1, 2),
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1280,6 +1335,11 @@ func Test_IsCollection(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "test check if nil is collection",
+			args: args{val: nil},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
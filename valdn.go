@@ -1,7 +1,6 @@
 package valdn
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -18,12 +17,19 @@ type (
 var (
 	TagName      = "valdn"
 	TagSeparator = "|"
+	// RootName is the rule key and error name of the root value, e.g. the array of a JSON document like [1,2].
+	RootName = "$"
 )
 
 type validation struct {
-	rules       Rules
-	errors      Errors
-	fieldsExist fieldsExist
+	rules          Rules
+	errors         Errors
+	fieldsExist    fieldsExist
+	formatPath     PathFormatter
+	discriminators []discriminator
+	// containers reports weather the collections validated by name are slices or arrays, so names of their missing
+	// fields are resolved to paths, see fieldPath
+	containers map[string]bool
 }
 
 // createNewValidation copies rules and initialise new validation with it.
@@ -33,6 +39,7 @@ func createNewValidation(rules Rules) *validation {
 		rules:       copyRules(rules),
 		errors:      make(Errors),
 		fieldsExist: make(fieldsExist),
+		containers:  make(map[string]bool),
 	}
 }
 
@@ -45,6 +52,13 @@ func Validate(name string, val interface{}, rules []string) error {
 			continue
 		}
 
+		if a, aName, aVal, ok := getAlias(r); ok {
+			if err := validateAlias(name, val, a, aName, aVal); err != nil {
+				return err
+			}
+			continue
+		}
+
 		rName, rVal, rFunc, rExist := getRuleInfo(r)
 		if !rExist {
 			panic("unknown rule: " + rName)
@@ -58,26 +72,35 @@ func Validate(name string, val interface{}, rules []string) error {
 }
 
 // ValidateCollection validates nested struct, nested map, nested slice and nested array by rules and returns Errors.
+// val itself is validated by the rules of RootName, and its direct nested fields by the rules of * (or .*).
 // It panics if val is not kind of struct, map, slice or array.
 // Unexported struct fields will be ignored.
 // If an error is found it will not check the rest of the field's rules and continue to the next field.
 // If a parent has error it's nested fields will not be validated.
+// Errors are keyed by the paths of the fields in dotted notation, see WithPathFormat for other notations.
+// Interface fields of structs are validated by the types of their values, and objects may have rules selected by the
+// values of their fields, see WithDiscriminator.
 // It panics if one of the rules is not registered.
-func ValidateCollection(val interface{}, rules Rules) Errors {
+func ValidateCollection(val interface{}, rules Rules, opts ...Option) Errors {
 	if !IsCollection(val) {
 		panic(fmt.Errorf("ValidateCollection: val must be kind of struct, map, slice or array got %v", reflect.TypeOf(val).Kind()))
 	}
 
+	c := newConfig(opts)
 	v := createNewValidation(rules)
-	v.addTagRules(val, "")
+	v.formatPath = c.pathFormat
+	v.discriminators = c.discriminators
+	v.addTagRules(val, nil)
+	v.registerField(RootName)
 
+	rootRules := v.getParentRules("")
 	switch reflect.TypeOf(val).Kind() {
 	case reflect.Map:
-		v.validateMap(val, "")
+		v.validateMap(val, nil, "", rootRules)
 	case reflect.Slice, reflect.Array:
-		v.validateSlice(val, "")
+		v.validateSlice(val, nil, "", rootRules)
 	case reflect.Struct:
-		v.validateStruct(val, "")
+		v.validateStruct(val, nil, "", rootRules)
 	}
 
 	v.validateNonExistRequiredFields()
@@ -85,29 +108,81 @@ func ValidateCollection(val interface{}, rules Rules) Errors {
 	return v.errors
 }
 
-// ValidateJSON transforms JSON string to a map and validates it by rules and returns Errors.
+// ValidateJSON decodes JSON string and validates it by rules and returns Errors.
+// The JSON may be an object, an array or a scalar, the root is validated by the rules of RootName,
+// and the fields of objects and items of arrays by their names (name, 0.name) or by * for all of them.
 // If an error is found it will not check the rest of the field's rules and continue to the next field.
 // If parent has error it's nested fields will not be validated.
-// It panics if val is not JSON.
+// It panics if val is not JSON, if it exceeds the limits set using WithLimits,
+// or with JSONError if it's rejected by WithStrictJSON or WithDisallowUnknownFields.
 // It panics if one of the rules is not registered.
-func ValidateJSON(val string, rules Rules) Errors {
-	var jsonMap map[string]interface{}
+func ValidateJSON(val string, rules Rules, opts ...Option) Errors {
+	c := newConfig(opts)
+	if err := c.limits.checkBodySize(int64(len(val))); err != nil {
+		panic(err)
+	}
 
-	if err := json.Unmarshal([]byte(val), &jsonMap); err != nil {
+	root, err := decodeJSON([]byte(val), rules, c)
+	if err != nil {
 		panic(err)
 	}
-	return ValidateCollection(jsonMap, rules)
+	return validateRoot(root, rules, opts)
+}
+
+// validateRoot validates val by rules like ValidateCollection, but val may be a single value too,
+// then it's validated by the rules of RootName.
+func validateRoot(val interface{}, rules Rules, opts []Option) Errors {
+	if IsCollection(val) {
+		return ValidateCollection(val, rules, opts...)
+	}
+	v := createNewValidation(rules)
+	v.registerField(RootName)
+	if err := Validate(RootName, val, v.rules[RootName]); err != nil {
+		v.addError(RootName, err)
+	}
+	v.validateNonExistRequiredFields()
+	return v.errors
 }
 
 // ValidateRequest validates request by rules and returns Errors.
-// It validates request of content type: multipart/form-data, application/json and application/x-www-form-urlencoded.
+// It validates request of content type: multipart/form-data, application/json (and +json types),
+// application/x-www-form-urlencoded and application/xml (and text/xml and +xml types), see ValidateXML.
 // It validates url parameters.
-// It panics if body is not compatible with header content type.
+// Non JSON values are kept as strings unless their rules declare a type (e.g. int, float, bool, time), see WithLossless.
+// It panics with RequestError if body is not compatible with header content type, content type is not allowed,
+// or body exceeds the limits set using WithLimits.
 // It panics if one of the rules is not registered.
 // If an error is found it will not check the rest of the field's rules and continue to the next field.
-func ValidateRequest(r *http.Request, rules Rules) Errors {
-	m := parseRequest(r, rules)
-	return ValidateCollection(m, rules)
+func ValidateRequest(r *http.Request, rules Rules, opts ...Option) Errors {
+	_, errs := validateRequest(r, rules, newConfig(opts))
+	return errs
+}
+
+// validateRequest parses r and validates its values by rules.
+// Values that couldn't be parsed as their rules declare are not validated, their parsing errors are returned instead.
+func validateRequest(r *http.Request, rules Rules, c *config) (map[string]interface{}, Errors) {
+	m, parseErrs := parseRequest(r, rules, c)
+	return m, validateParsed(m, rules, parseErrs, c)
+}
+
+// validateParsed validates parsed request values m by rules.
+// Values that have parsing errors are not validated, their parsing errors are returned instead.
+// Rules of RootName are not used, the root of a request is its JSON body, which is validated while it's parsed.
+// Errors are keyed by the path format set using WithPathFormat.
+func validateParsed(m map[string]interface{}, rules Rules, parseErrs Errors, c *config) Errors {
+	_, hasRoot := rules[RootName]
+	if len(parseErrs) > 0 || hasRoot {
+		rules = copyRules(rules)
+		for name := range parseErrs {
+			rules[name] = []string{"skip"}
+		}
+		delete(rules, RootName)
+	}
+	errs := ValidateCollection(m, rules, WithPathFormat(c.pathFormat), withDiscriminators(c.discriminators))
+	for name, err := range parseErrs {
+		errs[formatName(m, name, c.pathFormat)] = err
+	}
+	return errs
 }
 
 func (v *validation) registerField(name string) {
@@ -118,12 +193,64 @@ func (v *validation) addError(name string, err error) {
 	v.errors[name] = err.Error()
 }
 
+// errorName returns the name errors of the value at p, of dotted name, are keyed by, the root's errors are keyed by
+// RootName. Paths are formatted in dotted notation (name) unless the validation has a PathFormatter.
+func (v *validation) errorName(p Path, name string) string {
+	if len(p) == 0 {
+		return RootName
+	}
+	if v.formatPath == nil {
+		return name
+	}
+	return v.formatPath(append(Path(nil), p...))
+}
+
+// fieldPath returns the path of dotted name of a field: segments nested in slices and arrays are indexes, and
+// segments nested in other collections are keys, even if they're digits (e.g. m.0 of {"m": {}} is the key "0" of m).
+// Segments nested in collections that are not validated are considered indexes if they're digits, like splitName.
+func (v *validation) fieldPath(name string) Path {
+	p := splitName(name)
+	for i, s := range p {
+		isArray, ok := v.containers[p[:i].String()]
+		if !ok {
+			break
+		}
+		if !isArray {
+			p[i] = toString(s)
+		}
+	}
+	return p
+}
+
+// validate validates val at p, of dotted name, by rules, and reports weather it's valid.
+// Values that have no rules are valid, so their paths are not formatted.
+func (v *validation) validate(p Path, name string, val interface{}, rules []string) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	errName := v.errorName(p, name)
+	if err := Validate(errName, val, rules); err != nil {
+		v.addError(errName, err)
+		return false
+	}
+	return true
+}
+
+// getFieldRules returns the rules of the field of dotted name, fields that have no rules of their own have the
+// wildcard rules of their parent. The parent is the name before the last dot, use getNestedRules for fields whose
+// keys may have dots.
 func (v *validation) getFieldRules(name string) []string {
+	return v.getNestedRules(name, getParentName(name))
+}
+
+// getNestedRules returns the rules of the field of dotted name nested in the value of dotted name parName (empty for
+// the root), fields that have no rules of their own have the wildcard rules of parName.
+func (v *validation) getNestedRules(name, parName string) []string {
 	val, ok := v.rules[name]
 	if ok {
 		return val
 	}
-	return v.rules[getParentName(name)+".*"]
+	return v.getWildcardRules(parName)
 }
 
 func (v *validation) getParentRules(name string) []string {
@@ -132,15 +259,26 @@ func (v *validation) getParentRules(name string) []string {
 		return val
 	}
 	if name != "" {
-		return v.rules[getParentName(name)+".*"]
+		return v.getWildcardRules(getParentName(name))
+	}
+	if val, ok = v.rules[RootName]; ok {
+		return val
 	}
 	return []string{}
 }
 
-// addTagRules gets rules from struct tag for every field and adds them to field rules if field has no rules.
-func (v *validation) addTagRules(val interface{}, parName string) {
-	parName = makeParentNameJoinable(parName)
+// getWildcardRules returns the rules of parName's direct nested fields (parName.*), nested fields of the root
+// have rules of .* or *.
+func (v *validation) getWildcardRules(parName string) []string {
+	val, ok := v.rules[parName+".*"]
+	if !ok && parName == "" {
+		return v.rules["*"]
+	}
+	return val
+}
 
+// addTagRules gets rules from struct tag for every field and adds them to field rules if field has no rules.
+func (v *validation) addTagRules(val interface{}, parPath Path) {
 	// if kind is struct add every field's tag rules
 	// if kind is slice or array or map, loop through all fields to find structs
 	switch reflect.TypeOf(val).Kind() {
@@ -148,21 +286,21 @@ func (v *validation) addTagRules(val interface{}, parName string) {
 		for _, key := range reflect.ValueOf(val).MapKeys() {
 			value := reflect.ValueOf(val).MapIndex(key).Interface()
 			if IsCollection(value) {
-				v.addTagRules(value, parName+toString(key))
+				v.addTagRules(value, parPath.child(toString(key)))
 			}
 		}
 	case reflect.Slice, reflect.Array:
 		for i := 0; i < reflect.ValueOf(val).Len(); i++ {
 			value := reflect.ValueOf(val).Index(i).Interface()
 			if IsCollection(value) {
-				v.addTagRules(value, parName+toString(i))
+				v.addTagRules(value, parPath.child(i))
 			}
 		}
 	case reflect.Struct:
 		t := reflect.TypeOf(val)
 		for i := 0; i < t.NumField(); i++ {
 			f := t.Field(i)
-			name := parName + f.Name
+			name := parPath.join(f.Name).String()
 			tRules := f.Tag.Get(TagName)
 
 			// add tag rules only if field has no rules
@@ -175,101 +313,115 @@ func (v *validation) addTagRules(val interface{}, parName string) {
 				continue
 			}
 
+			// ignore unexported field (e.g. fields of time.Time)
+			if !reflect.ValueOf(val).Field(i).CanInterface() {
+				continue
+			}
+
 			// get struct field value and type
 			fTyp := f.Type
 			fVal := reflect.ValueOf(val).Field(i).Interface()
 
 			switch fTyp.Kind() {
 			case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
-				v.addTagRules(fVal, name)
+				v.addTagRules(fVal, parPath.child(f.Name))
+			case reflect.Interface:
+				if IsCollection(fVal) {
+					v.addTagRules(fVal, parPath.child(f.Name))
+				}
 			}
 		}
 	}
 }
 
-func (v *validation) validateStruct(val interface{}, name string) {
-	r := v.getParentRules(name)
-
-	if err := Validate(name, val, r); err != nil {
-		v.addError(name, err)
+func (v *validation) validateStruct(val interface{}, p Path, name string, rules []string) {
+	v.containers[name] = false
+	if !v.validate(p, name, val, rules) {
 		return
 	}
 
+	v.addSchemaRules(p, rules)
+	v.addDiscriminatorRules(p, name, val)
 	typ := reflect.TypeOf(val)
 	value := reflect.ValueOf(val)
-	v.validateStructFields(typ, value, name)
+	v.validateStructFields(typ, value, p, name)
 }
 
-func (v *validation) validateMap(val interface{}, name string) {
-	r := v.getParentRules(name)
-	if err := Validate(name, val, r); err != nil {
-		v.addError(name, err)
+func (v *validation) validateMap(val interface{}, p Path, name string, rules []string) {
+	v.containers[name] = false
+	if !v.validate(p, name, val, rules) {
 		return
 	}
 
-	v.validateMapFields(convertInterfaceToMap(val), name)
+	v.addSchemaRules(p, rules)
+	v.addDiscriminatorRules(p, name, val)
+	v.validateMapFields(convertInterfaceToMap(val), p, name)
 }
 
-func (v *validation) validateSlice(val interface{}, name string) {
-	r := v.getParentRules(name)
-	if err := Validate(name, val, r); err != nil {
-		v.addError(name, err)
+func (v *validation) validateSlice(val interface{}, p Path, name string, rules []string) {
+	v.containers[name] = true
+	if !v.validate(p, name, val, rules) {
 		return
 	}
 
-	v.validateSliceFields(convertInterfaceToSlice(val), name)
+	v.addSchemaRules(p, rules)
+	v.validateSliceFields(convertInterfaceToSlice(val), p, name)
 }
 
-func (v *validation) validateByType(name string, t reflect.Type, val interface{}) {
+// validateByType validates val at p, of dotted name, by rules and by the rules of its nested fields if it's a
+// collection.
+func (v *validation) validateByType(p Path, name string, rules []string, t reflect.Type, val interface{}) {
 	v.registerField(name)
-	rules := v.getFieldRules(name)
 
 	if len(rules) > 0 && rules[0] == "skip" {
 		return
 	}
 
+	if t == nil {
+		// nil interface values (e.g. JSON null) have no type
+		v.validate(p, name, val, rules)
+		return
+	}
+
 	switch t.Kind() {
 	case reflect.Struct:
-		v.validateStruct(val, name)
+		v.validateStruct(val, p, name, rules)
 	case reflect.Map:
-		v.validateMap(val, name)
+		v.validateMap(val, p, name, rules)
 	case reflect.Slice, reflect.Array:
-		v.validateSlice(val, name)
+		v.validateSlice(val, p, name, rules)
 	default:
-		err := Validate(name, val, rules)
-		if err != nil {
-			v.addError(name, err)
-		}
+		v.validate(p, name, val, rules)
 	}
 }
 
-func (v *validation) validateStructFields(parTyp reflect.Type, parVal reflect.Value, parName string) {
-	parName = makeParentNameJoinable(parName)
+func (v *validation) validateStructFields(parTyp reflect.Type, parVal reflect.Value, parPath Path, parName string) {
 	for i := 0; i < parTyp.NumField(); i++ {
-		name, typ, val := getStructFieldInfo(i, parTyp, parVal, parName)
+		p, typ, val := getStructFieldInfo(i, parTyp, parVal, parPath)
 		// ignore unexported field
 		if !val.CanInterface() {
 			continue
 		}
-		v.validateByType(name, typ, val.Interface())
+		// interface fields are validated by the types of their values
+		if typ.Kind() == reflect.Interface {
+			typ = reflect.TypeOf(val.Interface())
+		}
+		name := parPath.childName(parName, p[len(p)-1])
+		v.validateByType(p, name, v.getNestedRules(name, parName), typ, val.Interface())
 	}
 }
 
-func (v *validation) validateMapFields(val map[string]interface{}, parName string) {
-	parName = makeParentNameJoinable(parName)
-	for name, value := range val {
-		name = parName + name
-		typ := reflect.TypeOf(value)
-		v.validateByType(name, typ, value)
+func (v *validation) validateMapFields(val map[string]interface{}, parPath Path, parName string) {
+	for key, value := range val {
+		name := parPath.childName(parName, key)
+		v.validateByType(parPath.child(key), name, v.getNestedRules(name, parName), reflect.TypeOf(value), value)
 	}
 }
 
-func (v *validation) validateSliceFields(val []interface{}, parName string) {
-	parName = makeParentNameJoinable(parName)
+func (v *validation) validateSliceFields(val []interface{}, parPath Path, parName string) {
 	for idx, value := range val {
-		name := parName + toString(idx)
-		typ := reflect.TypeOf(value)
-		v.validateByType(name, typ, value)
+		name := parPath.childName(parName, idx)
+		v.validateByType(parPath.child(idx), name, v.getNestedRules(name, parName), reflect.TypeOf(value), value)
 	}
 }
 
@@ -278,14 +430,13 @@ func (v *validation) validateNonExistRequiredFields() {
 		if name == "*" {
 			continue
 		}
-		for _, r := range rules {
-			rName, rVal := splitRuleNameAndRuleValue(r)
-			if rName == "required" {
-				_, ok := v.fieldsExist[name]
-				if !ok {
-					v.addError(name, errors.New(GetErrMsg("required", rVal, name, "")))
-				}
-			}
+		rName, rVal, ok := findRequired(rules)
+		if !ok {
+			continue
+		}
+		if _, ok = v.fieldsExist[name]; !ok {
+			errName := v.errorName(v.fieldPath(name), name)
+			v.addError(errName, errors.New(GetErrMsg(rName, rVal, errName, "")))
 		}
 	}
 }
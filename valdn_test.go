@@ -3,7 +3,9 @@ package valdn
 import (
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -25,7 +27,7 @@ func Test_createNewValidation(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			want := &validation{rules: Rules{"test": {"required"}}, errors: make(Errors), fieldsExist: make(fieldsExist)}
+			want := &validation{rules: Rules{"test": {"required"}}, errors: make(Errors), fieldsExist: make(fieldsExist), containers: make(map[string]bool)}
 			if got := createNewValidation(tt.args.rules); !reflect.DeepEqual(want, got) {
 				t.Errorf("createNewValidation() = %v, want = %v", got, want)
 			}
@@ -121,9 +123,9 @@ func Test_ValidateCollection(t *testing.T) {
 	}
 	type User struct {
 		ID          int64     `json:"id" db:"id"`
-		Name        string    `json:"name" db:"name" valdn:"required|minLen:5:maxLen:30"`
+		Name        string    `json:"name" db:"name" valdn:"required|minLen:5|maxLen:30"`
 		Email       string    `json:"email" db:"email" valdn:"required|email"`
-		Phone       string    `json:"phone" db:"phone" valdn:"required|minLen:5:maxLen:20"`
+		Phone       string    `json:"phone" db:"phone" valdn:"required|minLen:5|maxLen:20"`
 		CountryCode string    `json:"country_code" db:"country_code" valdn:"required|len:2"`
 		CreatedAt   time.Time `json:"created_at" db:"created_at" valdn:"skip"`
 		UpdatedAt   time.Time `json:"updated_at" db:"updated_at" valdn:"skip"`
@@ -178,6 +180,14 @@ func Test_ValidateCollection(t *testing.T) {
 			want:      Errors{},
 			wantPanic: false,
 		},
+		{
+			name: "test validate collection with time.Time values",
+			args: args{
+				val:   map[string]interface{}{"created_at": time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)},
+				rules: Rules{"created_at": {"required", "time"}},
+			},
+			want: Errors{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -401,6 +411,7 @@ func Test_ValidateJSON(t *testing.T) {
 	type args struct {
 		val   string
 		rules Rules
+		opts  []Option
 	}
 	tests := []struct {
 		name      string
@@ -436,26 +447,217 @@ func Test_ValidateJSON(t *testing.T) {
 			wantPanic: true,
 			want:      Errors{},
 		},
+		{
+			name: `test validate json within limits`,
+			args: args{
+				val:   `{"rName":"Ramses", "tags":["a","b"]}`,
+				rules: Rules{"rName": {"required"}},
+				opts:  []Option{WithLimits(Limits{MaxBodyBytes: 64, MaxDepth: 2, MaxKeys: 2, MaxArrayLen: 2})},
+			},
+			wantPanic: false,
+			want:      Errors{},
+		},
+		{
+			name: `test validate json with too large string`,
+			args: args{
+				val:   `{"rName":"Ramses"}`,
+				rules: Rules{},
+				opts:  []Option{WithLimits(Limits{MaxBodyBytes: 8})},
+			},
+			wantPanic: true,
+			want:      Errors{},
+		},
+		{
+			name: `test validate json with duplicate key in strict mode`,
+			args: args{
+				val:   `{"rName":"Ramses", "rName":"Narmer"}`,
+				rules: Rules{"rName": {"required"}},
+				opts:  []Option{WithStrictJSON()},
+			},
+			wantPanic: true,
+			want:      Errors{},
+		},
+		{
+			name: `test validate json with unknown field`,
+			args: args{
+				val:   `{"rName":"Ramses", "role":"admin"}`,
+				rules: Rules{"rName": {"required"}},
+				opts:  []Option{WithDisallowUnknownFields()},
+			},
+			wantPanic: true,
+			want:      Errors{},
+		},
+		{
+			name: `test validate json with known fields`,
+			args: args{
+				val:   `{"rName":"Ramses"}`,
+				rules: Rules{"rName": {"required"}},
+				opts:  []Option{WithStrictJSON(), WithDisallowUnknownFields()},
+			},
+			wantPanic: false,
+			want:      Errors{},
+		},
+		{
+			name: `test validate json with too long array`,
+			args: args{
+				val:   `{"tags":["a","b","c"]}`,
+				rules: Rules{},
+				opts:  []Option{WithLimits(Limits{MaxArrayLen: 2})},
+			},
+			wantPanic: true,
+			want:      Errors{},
+		},
+		{
+			name: `test validate json with integer above 2^53`,
+			args: args{
+				val:   `{"id":9007199254740993}`,
+				rules: Rules{"id": {"int", "max:9007199254740992"}},
+			},
+			wantPanic: false,
+			want: Errors{
+				"id": GetErrMsg("max", "9007199254740992", "id", 0),
+			},
+		},
+		{
+			name: `test validate json with exact decimals`,
+			args: args{
+				val:   `{"price":0.1,"ratio":1.0,"big":100000000000000000000000000000,"precise":0.12345678901234567890123}`,
+				rules: Rules{"price": {"max:0.1"}, "ratio": {"kind:float64"}, "big": {"int", "min:1e29"}, "precise": {"float", "between:0.1234567890123456789012,0.1234567890123456789013"}},
+			},
+			wantPanic: false,
+			want:      Errors{},
+		},
+		{
+			name: `test validate json with array root`,
+			args: args{
+				val:   `[{"name":"Narmer"},{"name":1}]`,
+				rules: Rules{"$": {"required", "maxLen:2"}, "*": {"kind:map"}, "0.name": {"kind:string"}, "1.name": {"kind:string"}},
+			},
+			wantPanic: false,
+			want: Errors{
+				"1.name": GetErrMsg("kind", "string", "1.name", 1),
+			},
+		},
+		{
+			name: `test validate json with invalid array root`,
+			args: args{
+				val:   `[{"name":"Narmer"},{"name":1}]`,
+				rules: Rules{"$": {"required", "minLen:3"}, "1.name": {"kind:string"}},
+			},
+			wantPanic: false,
+			want: Errors{
+				"$": GetErrMsg("minLen", "3", "$", ""),
+			},
+		},
+		{
+			name: `test validate json with scalar root`,
+			args: args{
+				val:   `"Narmer"`,
+				rules: Rules{"$": {"required", "maxLen:3"}},
+			},
+			wantPanic: false,
+			want:      Errors{"$": GetErrMsg("maxLen", "3", "$", "")},
+		},
+		{
+			name: `test validate json with null root`,
+			args: args{
+				val:   `null`,
+				rules: Rules{"$": {"required"}},
+			},
+			wantPanic: false,
+			want:      Errors{"$": GetErrMsg("required", "", "$", "")},
+		},
+		{
+			name: `test validate json with null field`,
+			args: args{
+				val:   `{"name":null}`,
+				rules: Rules{"name": {"required"}},
+			},
+			wantPanic: false,
+			want:      Errors{"name": GetErrMsg("required", "", "name", "")},
+		},
+		{
+			name: `test validate json with bracket paths`,
+			args: args{
+				val:   `[{"tags":["a",1]}]`,
+				rules: Rules{"0.tags.*": {"kind:string"}},
+				opts:  []Option{WithPathFormat(BracketPath)},
+			},
+			wantPanic: false,
+			want:      Errors{"[0].tags[1]": GetErrMsg("kind", "string", "[0].tags[1]", 1)},
+		},
+		{
+			name: `test validate json with wildcard rules of keys with dots`,
+			args: args{
+				val:   `{"a.b":1,"m":{"c.d":2}}`,
+				rules: Rules{"*": {"max:0"}, "m": {"kind:map"}, "m.*": {"max:1"}},
+			},
+			wantPanic: false,
+			want: Errors{
+				"a.b":   GetErrMsg("max", "0", "a.b", 1),
+				"m.c.d": GetErrMsg("max", "1", "m.c.d", 2),
+			},
+		},
+		{
+			name: `test validate json with bracket paths of digit map keys`,
+			args: args{
+				val:   `{"m":{"1":1}}`,
+				rules: Rules{"m": {"kind:map"}, "m.0": {"required"}, "m.1": {"kind:string"}},
+				opts:  []Option{WithPathFormat(BracketPath)},
+			},
+			wantPanic: false,
+			want: Errors{
+				`m["0"]`: GetErrMsg("required", "", `m["0"]`, ""),
+				`m["1"]`: GetErrMsg("kind", "string", `m["1"]`, 1),
+			},
+		},
+		{
+			name: `test validate json with bracket paths of slice indexes`,
+			args: args{
+				val:   `{"m":[]}`,
+				rules: Rules{"m": {"kind:slice"}, "m.0": {"required"}},
+				opts:  []Option{WithPathFormat(BracketPath)},
+			},
+			wantPanic: false,
+			want:      Errors{"m[0]": GetErrMsg("required", "", "m[0]", "")},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			defer func() {
-				if e := recover(); (e != nil) && !tt.wantPanic {
+				if e := recover(); (e != nil) != tt.wantPanic {
 					t.Errorf("ValidateJson() panicEr = %v, wantPanic %v, args %v", e, tt.wantPanic, tt.args)
 				}
 			}()
-			got := ValidateJSON(tt.args.val, tt.args.rules)
+			got := ValidateJSON(tt.args.val, tt.args.rules, tt.args.opts...)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ValidateJSON() = %v, want %v", got, tt.want)
 			}
 		})
 	}
+
+	kept := make(map[string]Path)
+	keep := func(p Path) string {
+		kept[DottedPath(p)] = p
+		return DottedPath(p)
+	}
+	ValidateJSON(`{"a":{"b":{"c":{"x":1,"y":2}}}}`, Rules{"a.b.c.*": {"max:0"}}, WithPathFormat(keep))
+	want := map[string]Path{"a.b.c.x": {"a", "b", "c", "x"}, "a.b.c.y": {"a", "b", "c", "y"}}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("ValidateJSON() formatted paths = %v, want %v", kept, want)
+	}
 }
 
 func Test_ValidateRequest(t *testing.T) {
+	jsonRequestWithParams := func(target string, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
 	type args struct {
 		r     *http.Request
 		rules Rules
+		opts  []Option
 	}
 	tests := []struct {
 		name      string
@@ -499,6 +701,147 @@ func Test_ValidateRequest(t *testing.T) {
 			wantPanic: false,
 			want:      Errors{"lang": GetErrMsg("kind", "int", "lang", "go")},
 		},
+		{
+			name: "test ValidateRequest with namespaces",
+			args: args{
+				r: namespacesRequest(),
+				rules: Rules{
+					"header.X-Request-ID":  {"required", "uuid"},
+					"header.Authorization": {"required", "regex:^Bearer [A-Za-z0-9._~+/-]+=*$"},
+					"cookie.session":       {"required"},
+					"path.id":              {"required", "int"},
+					"query.lang":           {"in:go"},
+				},
+				opts: []Option{WithNamespaces()},
+			},
+			wantPanic: false,
+			want: Errors{
+				"header.Authorization": GetErrMsg("required", "", "header.Authorization", ""),
+				"query.lang":           GetErrMsg("in", "go", "query.lang", "js"),
+			},
+		},
+		{
+			name: "test ValidateRequest with body field named as namespace",
+			args: args{
+				r:     jsonRequestWithParams("http://example.com", `{"path":{"x":"a/b"},"query":"go"}`),
+				rules: Rules{"path": {"required"}, "path.x": {"required", "minLen:5"}, "query": {"in:go"}},
+			},
+			wantPanic: false,
+			want:      Errors{"path.x": GetErrMsg("minLen", "5", "path.x", "a/b")},
+		},
+		{
+			name: "test ValidateRequest with body field named as enabled namespace",
+			args: args{
+				r:     jsonRequestWithParams("http://example.com", `{"path":{"x":"a/b"}}`),
+				rules: Rules{"path.x": {"required"}},
+				opts:  []Option{WithNamespaces()},
+			},
+			wantPanic: false,
+			want:      Errors{"path": "path is reserved for path values", "path.x": GetErrMsg("required", "", "path.x", "")},
+		},
+		{
+			name: "test ValidateRequest with typed url params",
+			args: args{
+				r:     httptest.NewRequest(http.MethodGet, "http://example.com?zip=01234&age=abc&active=true", strings.NewReader("")),
+				rules: Rules{"zip": {"required", "len:5"}, "age": {"required", "int", "min:18"}, "active": {"bool"}},
+			},
+			wantPanic: false,
+			want:      Errors{"age": GetErrMsg("int", "", "age", "abc")},
+		},
+		{
+			name: "test ValidateRequest with lossless url params",
+			args: args{
+				r:     httptest.NewRequest(http.MethodGet, "http://example.com?zip=01234&age=20", strings.NewReader("")),
+				rules: Rules{"zip": {"required", "len:5"}, "age": {"required", "kind:string"}},
+				opts:  []Option{WithLossless()},
+			},
+			wantPanic: false,
+			want:      Errors{},
+		},
+		{
+			name: "test ValidateRequest with duplicate rejected",
+			args: args{
+				r:     namespacesRequest(),
+				rules: Rules{"lang": {"required"}, "email": {"required", "email"}},
+				opts:  []Option{WithMergePolicy(MergeReject)},
+			},
+			wantPanic: false,
+			want:      Errors{"lang": "lang must not be in both body and url params"},
+		},
+		{
+			name: "test ValidateRequest with bracket url params",
+			args: args{
+				r:     httptest.NewRequest(http.MethodGet, "http://example.com?items[0][qty]=2&items[1][qty]=x", strings.NewReader("")),
+				rules: Rules{"items": {"required", "maxLen:5"}, "items.0.qty": {"required", "int", "min:1"}, "items.1.qty": {"required", "int", "min:1"}},
+			},
+			wantPanic: false,
+			want:      Errors{"items.1.qty": GetErrMsg("int", "", "items.1.qty", "x")},
+		},
+		{
+			name: "test ValidateRequest with nested form fields",
+			args: args{
+				r:     nestedURLEncodedRequest(),
+				rules: Rules{"address": {"required"}, "address.city": {"required", "in:cairo"}, "address.zip": {"required", "int"}, "items.1.qty": {"int", "min:3"}},
+			},
+			wantPanic: false,
+			want:      Errors{"items.1.qty": GetErrMsg("min", "3", "items.1.qty", 2)},
+		},
+		{
+			name: "test ValidateRequest with pinned source",
+			args: args{
+				r:     jsonRequestWithParams("http://example.com?role=admin", `{"name":"go"}`),
+				rules: Rules{"name": {"required"}, "role": {"source:body"}},
+			},
+			wantPanic: false,
+			want:      Errors{"role": GetErrMsg("source", "body", "role", "admin")},
+		},
+		{
+			name: "test ValidateRequest with strict sources",
+			args: args{
+				r:     httptest.NewRequest(http.MethodGet, "http://example.com?role=user&role=admin&tags=a&tags=b", strings.NewReader("")),
+				rules: Rules{"role": {"required", "in:user,admin"}, "tags": {"kind:slice"}},
+				opts:  []Option{WithStrictSources()},
+			},
+			wantPanic: false,
+			want:      Errors{"role": "role must have one value"},
+		},
+		{
+			name: "test ValidateRequest with strict sources of nested url params",
+			args: args{
+				r:     httptest.NewRequest(http.MethodGet, "http://example.com?items[0][sku]=a&items[0][sku]=b", strings.NewReader("")),
+				rules: Rules{"items": {"kind:slice"}, "items.0.sku": {"required"}},
+				opts:  []Option{WithStrictSources()},
+			},
+			wantPanic: false,
+			want:      Errors{"items.0.sku": "items.0.sku must have one value"},
+		},
+		{
+			name: "test ValidateRequest with pinned source of nested value",
+			args: args{
+				r:     jsonRequestWithParams("http://example.com", `{"user":{"name":"narmer","role":"admin"}}`),
+				rules: Rules{"user": {"required"}, "user.role": {"source:query"}},
+			},
+			wantPanic: false,
+			want:      Errors{"user.role": GetErrMsg("source", "query", "user.role", "admin")},
+		},
+		{
+			name: "test ValidateRequest with strict sources of headers and cookies",
+			args: args{
+				r: func() *http.Request {
+					r := httptest.NewRequest(http.MethodGet, "http://example.com", strings.NewReader(""))
+					r.Header.Add("X-Request-ID", "f47ac10b-58cc-0372-8567-0e02b2c3d479")
+					r.Header.Add("X-Request-ID", "f47ac10b-58cc-0372-8567-0e02b2c3d480")
+					r.AddCookie(&http.Cookie{Name: "session", Value: "a"})
+					r.AddCookie(&http.Cookie{Name: "session", Value: "b"})
+					return r
+				}(),
+				rules: Rules{"header.X-Request-ID": {"uuid"}, "cookie.session": {"required"}},
+				opts:  []Option{WithStrictSources(), WithNamespaces()},
+			},
+			wantPanic: false,
+			want: Errors{"header.X-Request-ID": "header.X-Request-ID must have one value",
+				"cookie.session": "cookie.session must have one value"},
+		},
 		{
 			name: "test ValidateRequest with empty json",
 			args: args{
@@ -526,6 +869,40 @@ func Test_ValidateRequest(t *testing.T) {
 			wantPanic: true,
 			want:      Errors{},
 		},
+		{
+			name: "test ValidateRequest with json array body",
+			args: args{
+				r:     arrayJSONRequest(),
+				rules: Rules{"$": {"required", "kind:slice", "maxLen:1"}, "*": {"kind:map"}, "0.name": {"required"}, "1.age": {"min:40"}},
+			},
+			wantPanic: false,
+			want: Errors{
+				"$":     GetErrMsg("maxLen", "1", "$", ""),
+				"1.age": GetErrMsg("min", "40", "1.age", 30),
+			},
+		},
+		{
+			name: "test ValidateRequest with json scalar body",
+			args: args{
+				r:     jsonRequestWithParams("/", `"Narmer"`),
+				rules: Rules{"$": {"required", "kind:int"}},
+			},
+			wantPanic: false,
+			want:      Errors{"$": GetErrMsg("kind", "int", "$", "Narmer")},
+		},
+		{
+			name: "test ValidateRequest with json pointer paths",
+			args: args{
+				r:     httptest.NewRequest(http.MethodGet, "/?items[0][qty]=x&items[1][qty]=-1", nil),
+				rules: Rules{"items.*": {"kind:map"}, "items.0.qty": {"int"}, "items.1.qty": {"int", "min:0"}},
+				opts:  []Option{WithPathFormat(JSONPointerPath)},
+			},
+			wantPanic: false,
+			want: Errors{
+				"/items/0/qty": GetErrMsg("int", "", "items.0.qty", "x"),
+				"/items/1/qty": GetErrMsg("min", "0", "/items/1/qty", -1),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -534,7 +911,7 @@ func Test_ValidateRequest(t *testing.T) {
 					t.Errorf("ValidateRequest() panic = %v, wantPanic %v", e, tt.wantPanic)
 				}
 			}()
-			got := ValidateRequest(tt.args.r, tt.args.rules)
+			got := ValidateRequest(tt.args.r, tt.args.rules, tt.args.opts...)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ValidateRequest() = %v, want %v", got, tt.want)
 			}
@@ -623,6 +1000,18 @@ func Test_validation_getFieldRules(t *testing.T) {
 			args:   args{fieldName: "parent.test"},
 			want:   []string{"required", "king:string"},
 		},
+		{
+			name:   "test get field rules using * of root",
+			fields: fields{rules: Rules{"*": {"required"}}},
+			args:   args{fieldName: "0"},
+			want:   []string{"required"},
+		},
+		{
+			name:   "test get field rules using .* of root over *",
+			fields: fields{rules: Rules{".*": {"kind:map"}, "*": {"required"}}},
+			args:   args{fieldName: "0"},
+			want:   []string{"kind:map"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -666,6 +1055,12 @@ func Test_validation_getParentRules(t *testing.T) {
 			args:   args{fieldName: ""},
 			want:   []string{},
 		},
+		{
+			name:   "test get parent rules of root",
+			fields: fields{rules: Rules{"$": {"required", "kind:slice"}}},
+			args:   args{fieldName: ""},
+			want:   []string{"required", "kind:slice"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -769,7 +1164,7 @@ func Test_validation_addTagRules(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := createNewValidation(tt.args.rules)
-			v.addTagRules(tt.args.val, tt.args.parName)
+			v.addTagRules(tt.args.val, splitName(tt.args.parName))
 			if !reflect.DeepEqual(v.rules, tt.want) {
 				t.Errorf("addTagRules() = %v, want %v", v.rules, tt.want)
 			}
@@ -868,7 +1263,8 @@ func Test_validation_validateStruct(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := createNewValidation(tt.args.rules)
-			v.validateStruct(tt.args.val, tt.args.name)
+			p := splitName(tt.args.name)
+			v.validateStruct(tt.args.val, p, p.String(), v.getParentRules(p.String()))
 			if !reflect.DeepEqual(v.errors, tt.want) {
 				t.Errorf("validateStruct() = %v, want %v", v.errors, tt.want)
 			}
@@ -956,7 +1352,7 @@ func Test_validation_validateMap(t *testing.T) {
 				}
 			}()
 			v := createNewValidation(tt.args.rules)
-			v.validateMap(tt.args.val, "")
+			v.validateMap(tt.args.val, nil, "", v.getParentRules(""))
 			if !reflect.DeepEqual(v.errors, tt.want) {
 				t.Errorf("validateMap() = %v, want %v", v.errors, tt.want)
 			}
@@ -1044,7 +1440,7 @@ func Test_validation_validateSlice(t *testing.T) {
 				}
 			}()
 			v := createNewValidation(tt.args.rules)
-			v.validateSlice(tt.args.val, "")
+			v.validateSlice(tt.args.val, nil, "", v.getParentRules(""))
 			if !reflect.DeepEqual(v.errors, tt.want) {
 				t.Errorf("validateSlice() = %v, want %v", v.errors, tt.want)
 			}
@@ -1127,7 +1523,8 @@ func Test_validation_validateByType(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := createNewValidation(tt.args.rules)
-			v.validateByType(tt.args.name, tt.args.typ, tt.args.val)
+			p := splitName(tt.args.name)
+			v.validateByType(p, p.String(), v.getFieldRules(p.String()), tt.args.typ, tt.args.val)
 			if !reflect.DeepEqual(v.errors, tt.want) {
 				t.Errorf("validateByType() = %v, want %v", v.errors, tt.want)
 			}
@@ -1190,7 +1587,7 @@ func Test_validation_validateStructFields(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := createNewValidation(tt.args.rules)
-			v.validateStructFields(tt.args.t, tt.args.v, tt.args.parName)
+			v.validateStructFields(tt.args.t, tt.args.v, splitName(tt.args.parName), tt.args.parName)
 			if !reflect.DeepEqual(v.errors, tt.want) {
 				t.Errorf("validateStructFields() = %v, want %v", v.errors, tt.want)
 			}
@@ -1234,7 +1631,7 @@ func Test_validation_validateMapFields(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := createNewValidation(tt.args.rules)
-			v.validateMapFields(tt.args.val, tt.args.parName)
+			v.validateMapFields(tt.args.val, splitName(tt.args.parName), tt.args.parName)
 			if !reflect.DeepEqual(v.errors, tt.want) {
 				t.Errorf("validateMapFields() = %v, want %v", v.errors, tt.want)
 			}
@@ -1278,7 +1675,7 @@ func Test_validation_validateSliceFields(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := createNewValidation(tt.args.rules)
-			v.validateSliceFields(tt.args.val, tt.args.parName)
+			v.validateSliceFields(tt.args.val, splitName(tt.args.parName), tt.args.parName)
 			if !reflect.DeepEqual(v.errors, tt.want) {
 				t.Errorf("validateSliceFields() = %v, want %v", v.errors, tt.want)
 			}
@@ -1318,3 +1715,50 @@ func Test_validation_validateNonExistRequiredFields(t *testing.T) {
 		})
 	}
 }
+
+func Test_ValidateCollection_pathFormat(t *testing.T) {
+	val := map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"name": 1}},
+		"meta":  map[string]interface{}{"a/b": 1},
+	}
+	rules := Rules{"items.0.name": {"kind:string"}, "meta.a/b": {"kind:string"}, "title": {"required"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want Errors
+	}{
+		{
+			name: "test ValidateCollection with dotted paths",
+			want: Errors{
+				"items.0.name": GetErrMsg("kind", "string", "items.0.name", 1),
+				"meta.a/b":     GetErrMsg("kind", "string", "meta.a/b", 1),
+				"title":        GetErrMsg("required", "", "title", ""),
+			},
+		},
+		{
+			name: "test ValidateCollection with bracket paths",
+			opts: []Option{WithPathFormat(BracketPath)},
+			want: Errors{
+				"items[0].name": GetErrMsg("kind", "string", "items[0].name", 1),
+				`meta["a/b"]`:   GetErrMsg("kind", "string", `meta["a/b"]`, 1),
+				"title":         GetErrMsg("required", "", "title", ""),
+			},
+		},
+		{
+			name: "test ValidateCollection with json pointer paths",
+			opts: []Option{WithPathFormat(JSONPointerPath)},
+			want: Errors{
+				"/items/0/name": GetErrMsg("kind", "string", "/items/0/name", 1),
+				"/meta/a~1b":    GetErrMsg("kind", "string", "/meta/a~1b", 1),
+				"/title":        GetErrMsg("required", "", "/title", ""),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateCollection(val, rules, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateCollection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package valdn
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// xmlElement is an element of an XML document before it's converted to a value by rules.
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlElement
+	text     strings.Builder
+}
+
+// decodeXML decodes XML document data into a map that has its root element, elements and attributes are named by
+// dotted paths (order.item.0.@sku), see xmlValue.
+// If charset is not empty data is converted from it, otherwise from the encoding the document declares.
+// It returns error if data is not XML, or it exceeds the limits of l.
+func decodeXML(data []byte, rules Rules, l Limits, charset string) (map[string]interface{}, error) {
+	data, err := decodeCharset(data, charset)
+	if err != nil {
+		return nil, err
+	}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if charset != "" {
+			// data is already converted to UTF-8
+			return input, nil
+		}
+		enc, err := htmlindex.Get(label)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedCharset, label)
+		}
+		return enc.NewDecoder().Reader(input), nil
+	}
+
+	maxDepth := l.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = maxXMLDepth
+	}
+	var root *xmlElement
+	var stack []*xmlElement
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root != nil && len(stack) == 0 {
+				return nil, fmt.Errorf("%w: <%v> at offset %v", ErrTrailingData, t.Name.Local, d.InputOffset())
+			}
+			if len(stack) >= maxDepth {
+				return nil, fmt.Errorf("%w: %v is nested more than %v levels", ErrTooDeep, xmlStackName(stack), maxDepth)
+			}
+			el := &xmlElement{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) == 0 {
+				root = el
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: document has no root element", io.ErrUnexpectedEOF)
+	}
+
+	m := map[string]interface{}{root.name: xmlValue(root, ruledName(rules, root.name), rules)}
+	if err = l.checkValue("", m, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// maxXMLDepth is the max nesting depth of XML documents if Limits.MaxDepth is not set, like maxJSONDepth.
+const maxXMLDepth = 10000
+
+// xmlStackName returns the dotted name of the innermost element of stack.
+func xmlStackName(stack []*xmlElement) string {
+	names := make([]string, len(stack))
+	for i, el := range stack {
+		names[i] = el.name
+	}
+	return strings.Join(names, ".")
+}
+
+// xmlValue converts el named name to a value, name is empty if rules have no rules of el or of its nested elements
+// (see ruledName), so the names of deeply nested elements are not built.
+// Elements that have no attributes and no child elements are converted to their trimmed text.
+// Other elements are converted to maps of their attributes (prefixed with @) and child elements, and their text
+// (named #text) if it's not blank. Child elements that repeat, or whose rules expect a list, are converted to slices.
+func xmlValue(el *xmlElement, name string, rules Rules) interface{} {
+	text := strings.TrimSpace(el.text.String())
+	if len(el.attrs) == 0 && len(el.children) == 0 {
+		return text
+	}
+
+	m := make(map[string]interface{})
+	for _, a := range el.attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		m["@"+a.Name.Local] = a.Value
+	}
+	groups := make(map[string][]*xmlElement)
+	var order []string
+	for _, c := range el.children {
+		if _, ok := groups[c.name]; !ok {
+			order = append(order, c.name)
+		}
+		groups[c.name] = append(groups[c.name], c)
+	}
+	for _, childName := range order {
+		children := groups[childName]
+		fullName := ""
+		if name != "" {
+			fullName = ruledName(rules, name+"."+childName)
+		}
+		if len(children) == 1 && (fullName == "" || !expectsList(rules, fullName)) {
+			m[childName] = xmlValue(children[0], fullName, rules)
+			continue
+		}
+		s := make([]interface{}, len(children))
+		for i, c := range children {
+			itemName := ""
+			if fullName != "" {
+				itemName = ruledName(rules, fullName+"."+strconv.Itoa(i))
+			}
+			s[i] = xmlValue(c, itemName, rules)
+		}
+		m[childName] = s
+	}
+	if text != "" {
+		m["#text"] = text
+	}
+	return m
+}
+
+// ruledName returns name if rules have rules of name or of names nested in it, otherwise it returns an empty name.
+func ruledName(rules Rules, name string) string {
+	prefix := name + "."
+	for k := range rules {
+		if k == name || strings.HasPrefix(k, prefix) {
+			return name
+		}
+	}
+	return ""
+}
+
+// expectsList reports weather rules of name expect a slice or an array, by kind rules or rules of its indexes
+// (name.0, name.1.sku).
+func expectsList(rules Rules, name string) bool {
+	for _, r := range expandAliases(rules[name]) {
+		rName, rVal := splitRuleNameAndRuleValue(r)
+		if (rName == "kind" || rName == "kindIn") && (strings.Contains(rVal, "slice") || strings.Contains(rVal, "array")) {
+			return true
+		}
+	}
+	prefix := name + "."
+	for k := range rules {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		segment := strings.SplitN(k[len(prefix):], ".", 2)[0]
+		if _, err := strconv.Atoi(segment); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateXML decodes XML string and validates it by rules and returns Errors.
+// Elements and attributes are named by dotted paths that start with the root element: order.item.@sku is the sku
+// attribute of the item element of the order element, and order.item.0.@sku is of the first item element if it
+// repeats. Repeated elements are converted to slices, and elements whose rules expect a list (kind:slice or rules of
+// their indexes) are converted to slices even if they don't repeat.
+// Elements that have no attributes and no child elements are converted to their trimmed text, other elements to maps,
+// and their non blank text is named #text.
+// Values are kept as strings unless their rules declare a type (e.g. int, float, bool, time), see WithLossless.
+// If an error is found it will not check the rest of the field's rules and continue to the next field.
+// It panics if val is not XML, or if it exceeds the limits set using WithLimits.
+// It panics if one of the rules is not registered.
+func ValidateXML(val string, rules Rules, opts ...Option) Errors {
+	c := newConfig(opts)
+	if err := c.limits.checkBodySize(int64(len(val))); err != nil {
+		panic(err)
+	}
+	m, err := decodeXML([]byte(val), rules, c.limits, "")
+	if err != nil {
+		panic(err)
+	}
+	parseErrs := make(Errors)
+	if !c.lossless {
+		convertReqVals(m, rules, nil, parseErrs)
+	}
+	return validateParsed(m, rules, parseErrs, c)
+}
@@ -0,0 +1,325 @@
+package valdn
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_isXMLMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      bool
+	}{
+		{mediaType: "application/xml", want: true},
+		{mediaType: "text/xml", want: true},
+		{mediaType: "application/atom+xml", want: true},
+		{mediaType: "application/json", want: false},
+	}
+	for _, tt := range tests {
+		t.Run("test isXMLMediaType with "+tt.mediaType, func(t *testing.T) {
+			if got := isXMLMediaType(tt.mediaType); got != tt.want {
+				t.Errorf("isXMLMediaType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_expectsList(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		want  bool
+	}{
+		{name: "test expectsList with kind rule", rules: Rules{"order.item": {"kind:slice"}}, want: true},
+		{name: "test expectsList with index rule", rules: Rules{"order.item.0.@sku": {"required"}}, want: true},
+		{name: "test expectsList with nested rule", rules: Rules{"order.item.@sku": {"required"}}, want: false},
+		{name: "test expectsList without rules", rules: Rules{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expectsList(tt.rules, "order.item"); got != tt.want {
+				t.Errorf("expectsList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ruledName(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		want  string
+	}{
+		{name: "test ruledName with rules of name", rules: Rules{"order.item": {"required"}}, want: "order.item"},
+		{name: "test ruledName with nested rules", rules: Rules{"order.item.0.@sku": {"required"}}, want: "order.item"},
+		{name: "test ruledName with rules of sibling", rules: Rules{"order.items": {"required"}}, want: ""},
+		{name: "test ruledName without rules", rules: Rules{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruledName(tt.rules, "order.item"); got != tt.want {
+				t.Errorf("ruledName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_decodeXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		rules   Rules
+		limits  Limits
+		charset string
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name: "test decodeXML",
+			data: `<?xml version="1.0"?>
+<order id="7" xmlns="urn:orders">
+	<customer> Narmer </customer>
+	<item sku="a1"><qty>2</qty></item>
+	<item sku="b2"><qty>1</qty></item>
+	<note lang="en">fragile</note>
+	<empty/>
+</order>`,
+			rules: Rules{},
+			want: map[string]interface{}{"order": map[string]interface{}{
+				"@id":      "7",
+				"customer": "Narmer",
+				"item": []interface{}{
+					map[string]interface{}{"@sku": "a1", "qty": "2"},
+					map[string]interface{}{"@sku": "b2", "qty": "1"},
+				},
+				"note":  map[string]interface{}{"@lang": "en", "#text": "fragile"},
+				"empty": "",
+			}},
+		},
+		{
+			name:  "test decodeXML with list rules",
+			data:  `<order><item sku="a1"/><tag>x</tag></order>`,
+			rules: Rules{"order.item.0.@sku": {"required"}, "order.tag": {"kind:slice"}},
+			want: map[string]interface{}{"order": map[string]interface{}{
+				"item": []interface{}{map[string]interface{}{"@sku": "a1"}},
+				"tag":  []interface{}{"x"},
+			}},
+		},
+		{
+			name:    "test decodeXML with declared encoding",
+			data:    "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><name>Ca\xeft</name>",
+			rules:   Rules{},
+			want:    map[string]interface{}{"name": "Caït"},
+			wantErr: nil,
+		},
+		{
+			name:    "test decodeXML with charset",
+			data:    "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><name>Ca\xeft</name>",
+			rules:   Rules{},
+			charset: "ISO-8859-1",
+			want:    map[string]interface{}{"name": "Caït"},
+		},
+		{
+			name:    "test decodeXML with unknown encoding",
+			data:    `<?xml version="1.0" encoding="x-unknown"?><name>a</name>`,
+			rules:   Rules{},
+			wantErr: ErrUnsupportedCharset,
+		},
+		{
+			name:    "test decodeXML with trailing element",
+			data:    `<a>1</a><b>2</b>`,
+			rules:   Rules{},
+			wantErr: ErrTrailingData,
+		},
+		{
+			name:    "test decodeXML with too deep document",
+			data:    `<a><b><c>1</c></b></a>`,
+			rules:   Rules{},
+			limits:  Limits{MaxDepth: 2},
+			wantErr: ErrTooDeep,
+		},
+		{
+			name:    "test decodeXML with too many items",
+			data:    `<a><b>1</b><b>2</b><b>3</b></a>`,
+			rules:   Rules{},
+			limits:  Limits{MaxArrayLen: 2},
+			wantErr: ErrArrayTooLong,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeXML([]byte(tt.data), tt.rules, tt.limits, tt.charset)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decodeXML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeXML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	deep := strings.Repeat("<a>", maxXMLDepth+1) + "1" + strings.Repeat("</a>", maxXMLDepth+1)
+	if _, err := decodeXML([]byte(deep), Rules{}, Limits{}, ""); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("decodeXML() of document nested %v levels error = %v, wantErr %v", maxXMLDepth+1, err, ErrTooDeep)
+	}
+
+	for _, data := range []string{``, `<a>`, `<a></b>`, `text`} {
+		if _, err := decodeXML([]byte(data), Rules{}, Limits{}, ""); err == nil {
+			t.Errorf("decodeXML(%q) error = nil, want error", data)
+		}
+	}
+}
+
+func Test_ValidateXML(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("<a>", depth) + "1" + strings.Repeat("</a>", depth)
+	}
+	order := `<order id="7"><customer>Narmer</customer><item sku="a1"><qty>2</qty></item><item sku=""><qty>x</qty></item></order>`
+	tests := []struct {
+		name      string
+		val       string
+		rules     Rules
+		opts      []Option
+		want      Errors
+		wantPanic bool
+	}{
+		{
+			name: "test ValidateXML",
+			val:  order,
+			rules: Rules{
+				"order":             {"required"},
+				"order.@id":         {"required", "int", "min:1"},
+				"order.customer":    {"required", "in:Narmer,Hor-Aha"},
+				"order.item":        {"required", "kind:slice", "maxLen:5"},
+				"order.item.0.@sku": {"required", "regex:^[a-z][0-9]$"},
+				"order.item.0.qty":  {"int", "min:1"},
+			},
+			want: Errors{},
+		},
+		{
+			name: "test ValidateXML with invalid values",
+			val:  order,
+			rules: Rules{
+				"order.@id":         {"int", "max:5"},
+				"order.note":        {"required"},
+				"order.item.1.@sku": {"required"},
+				"order.item.1.qty":  {"int"},
+			},
+			want: Errors{
+				"order.@id":         GetErrMsg("max", "5", "order.@id", 7),
+				"order.note":        GetErrMsg("required", "", "order.note", ""),
+				"order.item.1.@sku": GetErrMsg("required", "", "order.item.1.@sku", ""),
+				"order.item.1.qty":  GetErrMsg("int", "", "order.item.1.qty", "x"),
+			},
+		},
+		{
+			name:  "test ValidateXML with lossless values",
+			val:   order,
+			rules: Rules{"order.@id": {"kind:string"}},
+			opts:  []Option{WithLossless()},
+			want:  Errors{},
+		},
+		{
+			name:  "test ValidateXML with bracket paths",
+			val:   order,
+			rules: Rules{"order.item.1.@sku": {"required"}},
+			opts:  []Option{WithPathFormat(BracketPath)},
+			want:  Errors{`order.item[1]["@sku"]`: GetErrMsg("required", "", `order.item[1]["@sku"]`, "")},
+		},
+		{
+			name:      "test ValidateXML with malformed xml",
+			val:       `<order>`,
+			rules:     Rules{},
+			wantPanic: true,
+		},
+		{
+			name:  "test ValidateXML with deeply nested xml",
+			val:   nested(maxXMLDepth),
+			rules: Rules{"a": {"required"}},
+			want:  Errors{},
+		},
+		{
+			name:      "test ValidateXML with too deep xml",
+			val:       nested(maxXMLDepth + 1),
+			rules:     Rules{},
+			wantPanic: true,
+		},
+		{
+			name:      "test ValidateXML with too large xml",
+			val:       order,
+			rules:     Rules{},
+			opts:      []Option{WithLimits(Limits{MaxBodyBytes: 10})},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if e := recover(); (e != nil) != tt.wantPanic {
+					t.Errorf("ValidateXML() panic = %v, wantPanic %v", e, tt.wantPanic)
+				}
+			}()
+			if got := ValidateXML(tt.val, tt.rules, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateXML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateRequest_xml(t *testing.T) {
+	xmlRequest := func(contentType string, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/?page=x", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		return r
+	}
+	rules := Rules{"order.item.0.@sku": {"required"}, "order.item.0.qty": {"int", "min:1"}, "page": {"int"}}
+	tests := []struct {
+		name       string
+		req        *http.Request
+		want       Errors
+		wantStatus int
+	}{
+		{
+			name: "test ValidateRequest with application/xml",
+			req:  xmlRequest("application/xml", `<order><item sku="a1"><qty>0</qty></item></order>`),
+			want: Errors{
+				"order.item.0.qty": GetErrMsg("min", "1", "order.item.0.qty", 0),
+				"page":             GetErrMsg("int", "", "page", "x"),
+			},
+		},
+		{
+			name: "test ValidateRequest with text/xml and charset",
+			req:  xmlRequest("text/xml; charset=ISO-8859-1", "<order><item sku=\"\xe91\"><qty>1</qty></item></order>"),
+			want: Errors{"page": GetErrMsg("int", "", "page", "x")},
+		},
+		{
+			name:       "test ValidateRequest with malformed xml",
+			req:        xmlRequest("application/xml", `<order>`),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				e := recover()
+				re, _ := e.(*RequestError)
+				if (tt.wantStatus != 0) != (e != nil) || (re != nil && re.Status != tt.wantStatus) {
+					t.Errorf("ValidateRequest() panic = %v, wantStatus %v", e, tt.wantStatus)
+				}
+			}()
+			if got := ValidateRequest(tt.req, rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateRequest() = %v, want %v", got, tt.want)
+			}
+			body := new(strings.Builder)
+			_, _ = io.Copy(body, tt.req.Body)
+			if body.Len() == 0 {
+				t.Errorf("ValidateRequest() didn't restore the request body")
+			}
+		})
+	}
+}